@@ -0,0 +1,128 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// AUTO_SCHEME is the scheme of the `AutoBarcode` implementation, which is not itself a symbology.
+const AUTO_SCHEME string = "auto://"
+
+// AutoBarcode implements the `bcbp.Barcode` interface by trying every other registered `bcbp.Barcode`
+// implementation in turn and reporting which symbology matched.
+type AutoBarcode struct{}
+
+func init() {
+	ctx := context.Background()
+	bcbp.RegisterBarcode(ctx, "auto", NewAutoBarcode)
+}
+
+// NewAutoBarcode returns a new `AutoBarcode` instance configured by 'uri' which is expected to take the form of:
+//
+//	auto://
+func NewAutoBarcode(ctx context.Context, uri string) (bcbp.Barcode, error) {
+	bc := &AutoBarcode{}
+	return bc, nil
+}
+
+// Encode returns an error since a symbology must be chosen in order to encode a barcode.
+func (bc *AutoBarcode) Encode(b *bcbp.BCBP, wr io.Writer) error {
+	return fmt.Errorf("Encoding barcodes requires a symbology")
+}
+
+// Decode decodes the symbol, of any registered symbology, in the image data contained in 'r' and parses its text
+// as a BCBP string, in lenient mode.
+func (bc *AutoBarcode) Decode(r io.Reader) (*bcbp.BCBP, error) {
+
+	s, err := bc.DecodeSymbol(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSymbol(s)
+}
+
+// DecodeSymbol decodes the symbol in the image data contained in 'r' using each of the schemes returned by
+// `bcbp.BarcodeSchemes`, other than AUTO_SCHEME, in turn. The first symbol whose text can be parsed as a BCBP
+// string, in lenient mode, is returned. If no symbol can be parsed the first symbol which was decoded is returned.
+// The 'Symbology' property of the symbol reports which symbology matched.
+func (bc *AutoBarcode) DecodeSymbol(r io.Reader) (*Symbol, error) {
+
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read image data, %w", err)
+	}
+
+	im, err := readImage(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	var first *Symbol
+	tried := make([]string, 0)
+
+	for _, scheme := range bcbp.BarcodeSchemes() {
+
+		if scheme == AUTO_SCHEME {
+			continue
+		}
+
+		s, err := decodeScheme(ctx, scheme, im, data)
+
+		if err != nil {
+			tried = append(tried, scheme)
+			continue
+		}
+
+		opts := &boardingpass.UnmarshalOptions{
+			Lenient: true,
+		}
+
+		_, err = boardingpass.UnmarshalWithOptions(s.Text, opts)
+
+		if err == nil {
+			return s, nil
+		}
+
+		if first == nil {
+			first = s
+		}
+	}
+
+	if first != nil {
+		return first, nil
+	}
+
+	return nil, fmt.Errorf("No barcode found, tried %s", strings.Join(tried, ", "))
+}
+
+// decodeScheme reads the symbol in 'im', decoded from 'data', using the `bcbp.Barcode` instance for 'scheme'.
+func decodeScheme(ctx context.Context, scheme string, im image.Image, data []byte) (*Symbol, error) {
+
+	bc, err := bcbp.NewBarcode(ctx, scheme)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch d := bc.(type) {
+	case imageDecoder:
+		return d.decodeImage(im)
+	case SymbolDecoder:
+		return d.DecodeSymbol(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("Barcode for '%s' does not support decoding symbols", scheme)
+	}
+}
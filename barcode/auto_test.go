@@ -0,0 +1,192 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	qr_decoder "github.com/makiuchi-d/gozxing/qrcode/decoder"
+	qr_encoder "github.com/makiuchi-d/gozxing/qrcode/encoder"
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// TestAutoDecodeSymbol decodes symbols of each registered symbology, and ensures that the symbology which matched
+// is reported.
+func TestAutoDecodeSymbol(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		symbology string
+		text      string
+	}{
+		{"aztec", SYMBOLOGY_AZTEC, testBCBP},
+		{"pdf417", SYMBOLOGY_PDF417, testBCBP},
+		{"qr", SYMBOLOGY_QR, testBCBP},
+		{"datamatrix", SYMBOLOGY_DATAMATRIX, testBCBP},
+		{"not_bcbp", SYMBOLOGY_QR, "HELLO WORLD"},
+	}
+
+	bc, err := NewAutoBarcode(ctx, "auto://")
+
+	if err != nil {
+		t.Fatalf("Failed to create barcode, %v", err)
+	}
+
+	for _, tt := range tests {
+
+		data, err := encodeTestSymbol(ctx, tt.symbology, tt.text)
+
+		if err != nil {
+			t.Errorf("%s: failed to encode symbol, %v", tt.name, err)
+			continue
+		}
+
+		s, err := bc.(*AutoBarcode).DecodeSymbol(bytes.NewReader(data))
+
+		if err != nil {
+			t.Errorf("%s: failed to decode symbol, %v", tt.name, err)
+			continue
+		}
+
+		if s.Symbology != tt.symbology {
+			t.Errorf("%s: expected symbology %s, got %s", tt.name, tt.symbology, s.Symbology)
+		}
+
+		if s.Text != tt.text {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.text, s.Text)
+		}
+	}
+}
+
+// TestAutoNotFound ensures that an image without a symbol is rejected and that the error lists every scheme
+// which was tried.
+func TestAutoNotFound(t *testing.T) {
+
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+
+	err := writePNG(newMatrix(40, 40), &renderOptions{moduleSize: 4, quietZone: 2}, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to write image, %v", err)
+	}
+
+	_, err = DecodeSymbol(ctx, "auto://", &buf)
+
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	for _, scheme := range []string{"aztec://", "datamatrix://", "pdf417://", "qr://"} {
+
+		if !strings.Contains(err.Error(), scheme) {
+			t.Errorf("Expected error to list %s, got '%v'", scheme, err)
+		}
+	}
+}
+
+// TestAutoEncode ensures that encoding requires a symbology.
+func TestAutoEncode(t *testing.T) {
+
+	ctx := context.Background()
+
+	bc, err := NewAutoBarcode(ctx, "auto://")
+
+	if err != nil {
+		t.Fatalf("Failed to create barcode, %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = bc.Encode(&bcbp.BCBP{}, &buf)
+
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+// TestBarcodeSchemes ensures that every symbology in this package, and the auto:// scheme, is registered.
+func TestBarcodeSchemes(t *testing.T) {
+
+	registered := make(map[string]bool)
+
+	for _, scheme := range bcbp.BarcodeSchemes() {
+		registered[scheme] = true
+	}
+
+	for _, scheme := range []string{AUTO_SCHEME, "aztec://", "datamatrix://", "pdf417://", "qr://"} {
+
+		if !registered[scheme] {
+			t.Errorf("Expected %s to be registered", scheme)
+		}
+	}
+}
+
+// encodeTestSymbol returns 'text' encoded as a PNG image using 'symbology'. QR codes and Data Matrix barcodes,
+// which this package can not encode, are encoded using gozxing.
+func encodeTestSymbol(ctx context.Context, symbology string, text string) ([]byte, error) {
+
+	var buf bytes.Buffer
+	var m *Matrix
+
+	switch symbology {
+	case SYMBOLOGY_QR:
+
+		code, err := qr_encoder.Encoder_encodeWithoutHint(text, qr_decoder.ErrorCorrectionLevel_M)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bm := code.GetMatrix()
+		m = newMatrix(bm.GetWidth(), bm.GetHeight())
+
+		for y := 0; y < bm.GetHeight(); y++ {
+
+			for x := 0; x < bm.GetWidth(); x++ {
+				m.set(x, y, bm.Get(x, y) == 1)
+			}
+		}
+
+	case SYMBOLOGY_DATAMATRIX:
+
+		bm, err := datamatrix.NewDataMatrixWriter().Encode(text, gozxing.BarcodeFormat_DATA_MATRIX, 0, 0, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m = newMatrix(bm.GetWidth(), bm.GetHeight())
+
+		for y := 0; y < bm.GetHeight(); y++ {
+
+			for x := 0; x < bm.GetWidth(); x++ {
+				m.set(x, y, bm.Get(x, y))
+			}
+		}
+
+	default:
+
+		err := EncodeSymbol(ctx, symbology+"://", text, &buf)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	err := writePNG(m, &renderOptions{moduleSize: 4, quietZone: 4}, &buf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
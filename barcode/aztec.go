@@ -0,0 +1,173 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+
+	aztec_encoder "github.com/boombuler/barcode/aztec"
+	"github.com/makiuchi-d/gozxing/aztec"
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// SYMBOLOGY_AZTEC is the symbology of Aztec barcodes, the symbology used by most mobile boarding passes.
+const SYMBOLOGY_AZTEC string = "aztec"
+
+// AZTEC_ERROR_CORRECTION is the default minimum percentage of a rendered Aztec symbol used for error correction.
+const AZTEC_ERROR_CORRECTION int = aztec_encoder.DEFAULT_EC_PERCENT
+
+// AZTEC_MODULE_SIZE is the default width and height, in pixels, of each module of a rendered Aztec symbol.
+const AZTEC_MODULE_SIZE int = 4
+
+// AZTEC_QUIET_ZONE is the default width, in modules, of the blank margin surrounding a rendered Aztec symbol.
+const AZTEC_QUIET_ZONE int = 2
+
+// AztecBarcode implements the `bcbp.Barcode` interface for Aztec barcodes.
+type AztecBarcode struct {
+	errorCorrection int
+	render          *renderOptions
+}
+
+func init() {
+	ctx := context.Background()
+	bcbp.RegisterBarcode(ctx, "aztec", NewAztecBarcode)
+}
+
+// NewAztecBarcode returns a new `AztecBarcode` instance configured by 'uri' which is expected to take the form
+// of:
+//
+//	aztec://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+//   - `error_correction` The minimum percentage (5-95) of encoded symbols used for error correction. Default is
+//     AZTEC_ERROR_CORRECTION.
+//   - `module_size` The width and height, in pixels, of each module of encoded symbols. Default is
+//     AZTEC_MODULE_SIZE.
+//   - `quiet_zone` The width, in modules, of the blank margin surrounding encoded symbols. Default is
+//     AZTEC_QUIET_ZONE.
+func NewAztecBarcode(ctx context.Context, uri string) (bcbp.Barcode, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	error_correction, err := intParameter(q, "error_correction", AZTEC_ERROR_CORRECTION, 5, 95)
+
+	if err != nil {
+		return nil, err
+	}
+
+	render, err := newRenderOptions(q, AZTEC_MODULE_SIZE, AZTEC_QUIET_ZONE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bc := &AztecBarcode{
+		errorCorrection: error_correction,
+		render:          render,
+	}
+
+	return bc, nil
+}
+
+// Encode encodes 'b' as a BCBP string, with its legs concatenated as described by Resolution 792, and writes it
+// to 'wr' as an Aztec symbol in a PNG image.
+func (bc *AztecBarcode) Encode(b *bcbp.BCBP, wr io.Writer) error {
+
+	text, err := marshalSymbol(b)
+
+	if err != nil {
+		return fmt.Errorf("Failed to marshal BCBP, %w", err)
+	}
+
+	return bc.EncodeSymbol(text, wr)
+}
+
+// EncodeSymbol writes 'text' to 'wr' as an Aztec symbol in a PNG image.
+func (bc *AztecBarcode) EncodeSymbol(text string, wr io.Writer) error {
+
+	m, err := bc.EncodeMatrix(text)
+
+	if err != nil {
+		return err
+	}
+
+	return writePNG(m, bc.render, wr)
+}
+
+// EncodeSVG writes 'text' to 'wr' as an Aztec symbol in an SVG document using 'opts'.
+func (bc *AztecBarcode) EncodeSVG(text string, opts *SVGOptions, wr io.Writer) error {
+
+	m, err := bc.EncodeMatrix(text)
+
+	if err != nil {
+		return err
+	}
+
+	return writeSVG(m, bc.render, opts, wr)
+}
+
+// EncodeMatrix encodes 'text' as the modules of the smallest compact or full-range Aztec symbol which can hold it.
+func (bc *AztecBarcode) EncodeMatrix(text string) (*Matrix, error) {
+
+	code, err := aztec_encoder.Encode([]byte(text), bc.errorCorrection, 0)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode Aztec symbol, %w", err)
+	}
+
+	bounds := code.Bounds()
+	m := newMatrix(bounds.Dx(), bounds.Dy())
+
+	for y := 0; y < m.Height; y++ {
+
+		for x := 0; x < m.Width; x++ {
+			r, g, b, _ := code.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			m.set(x, y, r+g+b < 0x8000*3)
+		}
+	}
+
+	return m, nil
+}
+
+// Decode decodes the Aztec symbol in the image data contained in 'r' and parses its text as a BCBP string, in
+// lenient mode.
+func (bc *AztecBarcode) Decode(r io.Reader) (*bcbp.BCBP, error) {
+
+	s, err := bc.DecodeSymbol(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSymbol(s)
+}
+
+// DecodeSymbol decodes the Aztec symbol, full-range or compact, in the PNG, JPEG or GIF image data contained in
+// 'r'. The symbol may be at any orientation.
+func (bc *AztecBarcode) DecodeSymbol(r io.Reader) (*Symbol, error) {
+	return decodeSymbol(bc, r)
+}
+
+func (bc *AztecBarcode) decodeImage(im image.Image) (*Symbol, error) {
+
+	text, err := readZXing(aztec.NewAztecReader(), im)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Aztec symbol, %w", err)
+	}
+
+	s := &Symbol{
+		Symbology: SYMBOLOGY_AZTEC,
+		Text:      text,
+	}
+
+	return s, nil
+}
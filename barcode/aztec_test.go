@@ -0,0 +1,157 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"testing"
+)
+
+// TestAztecRoundTrip encodes text as an Aztec symbol in a PNG image, using a variety of parameters, and decodes it
+// again, including symbols which have been rotated.
+func TestAztecRoundTrip(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		uri     string
+		text    string
+		degrees float64
+	}{
+		{"bcbp", "aztec://", testBCBP, 0},
+		{"bcbp_multi_leg", "aztec://", testMultiLegBCBP, 0},
+		{"error_correction", "aztec://?error_correction=50", testBCBP, 0},
+		{"module_size", "aztec://?module_size=2&quiet_zone=4", testBCBP, 0},
+		{"compact", "aztec://", "M1", 0},
+		{"rotated_90", "aztec://", testBCBP, 90},
+		{"rotated_180", "aztec://", testBCBP, 180},
+		{"rotated_270", "aztec://", testBCBP, 270},
+	}
+
+	for _, tt := range tests {
+
+		bc, err := NewAztecBarcode(ctx, tt.uri)
+
+		if err != nil {
+			t.Errorf("%s: failed to create barcode, %v", tt.name, err)
+			continue
+		}
+
+		m, err := bc.(*AztecBarcode).EncodeMatrix(tt.text)
+
+		if err != nil {
+			t.Errorf("%s: failed to encode symbol, %v", tt.name, err)
+			continue
+		}
+
+		im := renderMatrix(m, 4, AZTEC_QUIET_ZONE)
+
+		if tt.degrees != 0 {
+			im = rotateImage(im, tt.degrees)
+		}
+
+		s, err := bc.(*AztecBarcode).decodeImage(im)
+
+		if err != nil {
+			t.Errorf("%s: failed to decode symbol, %v", tt.name, err)
+			continue
+		}
+
+		if s.Symbology != SYMBOLOGY_AZTEC {
+			t.Errorf("%s: expected symbology %s, got %s", tt.name, SYMBOLOGY_AZTEC, s.Symbology)
+		}
+
+		if s.Text != tt.text {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.text, s.Text)
+		}
+	}
+}
+
+// TestAztecDecode decodes an Aztec symbol in a PNG image as a BCBP string.
+func TestAztecDecode(t *testing.T) {
+
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+
+	raw := "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100DEF456 FRAGVALH 3664 227C012C0002 100"
+
+	err := EncodeSymbol(ctx, "aztec://", raw, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to encode symbol, %v", err)
+	}
+
+	bc, err := NewAztecBarcode(ctx, "aztec://")
+
+	if err != nil {
+		t.Fatalf("Failed to create barcode, %v", err)
+	}
+
+	b, err := bc.Decode(&buf)
+
+	if err != nil {
+		t.Fatalf("Failed to decode symbol, %v", err)
+	}
+
+	if len(b.Legs) != 2 {
+		t.Errorf("Expected 2 legs, got %d", len(b.Legs))
+	}
+}
+
+// TestAztecParameters ensures that invalid URI parameters are rejected.
+func TestAztecParameters(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		uri string
+		// Whether an error is expected.
+		err bool
+	}{
+		{"aztec://?error_correction=5", false},
+		{"aztec://?error_correction=95", false},
+		{"aztec://?error_correction=4", true},
+		{"aztec://?error_correction=96", true},
+		{"aztec://?error_correction=high", true},
+		{"aztec://?module_size=0", true},
+		{"aztec://?quiet_zone=-1", true},
+	}
+
+	for _, tt := range tests {
+
+		_, err := NewAztecBarcode(ctx, tt.uri)
+
+		if tt.err && err == nil {
+			t.Errorf("'%s': expected an error", tt.uri)
+		}
+
+		if !tt.err && err != nil {
+			t.Errorf("'%s': failed to create barcode, %v", tt.uri, err)
+		}
+	}
+}
+
+// rotateImage rotates im clockwise by a multiple of 90 degrees, moving whole pixels so that module edges stay sharp.
+func rotateImage(im *image.Gray, degrees float64) *image.Gray {
+
+	out := im
+
+	for i := 0; i < int(degrees)/90%4; i++ {
+
+		b := out.Bounds()
+		r := image.NewGray(image.Rect(0, 0, b.Dy(), b.Dx()))
+
+		for y := 0; y < b.Dy(); y++ {
+
+			for x := 0; x < b.Dx(); x++ {
+				r.SetGray(b.Dy()-1-y, x, out.GrayAt(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+
+		out = r
+	}
+
+	return out
+}
@@ -0,0 +1,89 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/sfomuseum/go-bcbp"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// Symbol is the text decoded from a barcode symbol.
+type Symbol struct {
+	// The symbology of the barcode. One of the SYMBOLOGY_ constants.
+	Symbology string `json:"symbology"`
+	// The text encoded by the barcode.
+	Text string `json:"text"`
+}
+
+// SymbolDecoder is implemented by the `bcbp.Barcode` instances in this package. It decodes the text of a barcode
+// without parsing it as a BCBP string, allowing the caller to parse it with options of their choosing.
+type SymbolDecoder interface {
+	// DecodeSymbol decodes the image data contained in an `io.Reader` instance as a `Symbol` instance.
+	DecodeSymbol(io.Reader) (*Symbol, error)
+}
+
+// imageDecoder is implemented by the `bcbp.Barcode` instances in this package. It reads the symbol in an image
+// which has already been decoded, allowing the same image to be read using several symbologies.
+type imageDecoder interface {
+	decodeImage(image.Image) (*Symbol, error)
+}
+
+// DecodeSymbol decodes the barcode symbol in the image data contained in 'r' using the `bcbp.Barcode`
+// instance configured by 'uri'.
+func DecodeSymbol(ctx context.Context, uri string, r io.Reader) (*Symbol, error) {
+
+	bc, err := bcbp.NewBarcode(ctx, uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create barcode for '%s', %w", uri, err)
+	}
+
+	d, ok := bc.(SymbolDecoder)
+
+	if !ok {
+		return nil, fmt.Errorf("Barcode for '%s' does not support decoding symbols", uri)
+	}
+
+	return d.DecodeSymbol(r)
+}
+
+// decodeSymbol decodes the PNG, JPEG or GIF image data contained in 'r' and reads its symbol using 'd'.
+func decodeSymbol(d imageDecoder, r io.Reader) (*Symbol, error) {
+
+	im, err := readImage(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.decodeImage(im)
+}
+
+// unmarshalSymbol parses the text of 's' as a BCBP string, in lenient mode, returning a `bcbp.BCBP` instance.
+func unmarshalSymbol(s *Symbol) (*bcbp.BCBP, error) {
+
+	opts := &boardingpass.UnmarshalOptions{
+		Lenient: true,
+	}
+
+	b, err := boardingpass.UnmarshalWithOptions(s.Text, opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s symbol, %w", s.Symbology, err)
+	}
+
+	legs := make([]*bcbp.Leg, len(b.Legs))
+
+	for idx, l := range b.Legs {
+		legs[idx] = l.Leg
+	}
+
+	bp := &bcbp.BCBP{
+		Legs: legs,
+	}
+
+	return bp, nil
+}
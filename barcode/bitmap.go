@@ -0,0 +1,183 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+)
+
+// bitmap is a binarized image where true is a dark (bar) module and false is a light (space) module. The luminance
+// of each pixel is kept so that the image can be resampled, see (*bitmap).sample.
+type bitmap struct {
+	width     int
+	height    int
+	bits      []bool
+	luminance []uint8
+	threshold int
+}
+
+// readImage decodes the PNG, JPEG or GIF image data in 'r'.
+func readImage(r io.Reader) (image.Image, error) {
+
+	im, _, err := image.Decode(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode image, %w", err)
+	}
+
+	return im, nil
+}
+
+// newBitmap returns a `bitmap` derived from 'im' using a global threshold chosen by Otsu's method. Transparent
+// pixels are treated as if they were drawn on a white background.
+func newBitmap(im image.Image) *bitmap {
+
+	bounds := im.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+
+	luminance := make([]uint8, width*height)
+	histogram := make([]int, 256)
+
+	for y := 0; y < height; y++ {
+
+		for x := 0; x < width; x++ {
+
+			r, g, b, a := im.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+
+			// RGBA returns alpha-premultiplied values so adding the missing alpha composites over white
+
+			r += 0xffff - a
+			g += 0xffff - a
+			b += 0xffff - a
+
+			l := uint8(((299*r + 587*g + 114*b) / 1000) >> 8)
+
+			luminance[y*width+x] = l
+			histogram[l] += 1
+		}
+	}
+
+	threshold := otsuThreshold(histogram, width*height)
+
+	bm := &bitmap{
+		width:     width,
+		height:    height,
+		bits:      make([]bool, width*height),
+		luminance: luminance,
+		threshold: threshold,
+	}
+
+	for idx, l := range luminance {
+		bm.bits[idx] = int(l) <= threshold
+	}
+
+	return bm
+}
+
+// otsuThreshold returns the luminance which best separates the two classes of pixels in 'histogram', the
+// luminance histogram of 'count' pixels.
+func otsuThreshold(histogram []int, count int) int {
+
+	sum := 0.0
+
+	for l, n := range histogram {
+		sum += float64(l * n)
+	}
+
+	sum_background := 0.0
+	weight_background := 0
+	best := 0.0
+	threshold := 127
+
+	for l, n := range histogram {
+
+		weight_background += n
+
+		if weight_background == 0 {
+			continue
+		}
+
+		weight_foreground := count - weight_background
+
+		if weight_foreground == 0 {
+			break
+		}
+
+		sum_background += float64(l * n)
+
+		mean_background := sum_background / float64(weight_background)
+		mean_foreground := (sum - sum_background) / float64(weight_foreground)
+
+		variance := float64(weight_background) * float64(weight_foreground) * (mean_background - mean_foreground) * (mean_background - mean_foreground)
+
+		if variance > best {
+			best = variance
+			threshold = l
+		}
+	}
+
+	return threshold
+}
+
+// row returns the modules of row 'y' of 'bm'.
+func (bm *bitmap) row(y int) []bool {
+	return bm.bits[y*bm.width : (y+1)*bm.width]
+}
+
+// rotate returns a copy of 'bm' rotated 90 degrees clockwise.
+func (bm *bitmap) rotate() *bitmap {
+
+	r := &bitmap{
+		width:     bm.height,
+		height:    bm.width,
+		bits:      make([]bool, len(bm.bits)),
+		luminance: make([]uint8, len(bm.luminance)),
+		threshold: bm.threshold,
+	}
+
+	for y := 0; y < bm.height; y++ {
+
+		for x := 0; x < bm.width; x++ {
+			r.bits[x*r.width+(bm.height-1-y)] = bm.bits[y*bm.width+x]
+			r.luminance[x*r.width+(bm.height-1-y)] = bm.luminance[y*bm.width+x]
+		}
+	}
+
+	return r
+}
+
+// sample reports whether the point ('x', 'y'), measured in pixels from the top left corner of 'bm', is dark. The
+// luminance at the point is interpolated from the four nearest pixels.
+func (bm *bitmap) sample(x float64, y float64) bool {
+
+	// Pixel centres are at half pixel offsets
+
+	x -= 0.5
+	y -= 0.5
+
+	x0 := int(math.Floor(x))
+	y0 := int(math.Floor(y))
+	fx := x - float64(x0)
+	fy := y - float64(y0)
+
+	top := bm.luminanceAt(x0, y0)*(1-fx) + bm.luminanceAt(x0+1, y0)*fx
+	bottom := bm.luminanceAt(x0, y0+1)*(1-fx) + bm.luminanceAt(x0+1, y0+1)*fx
+
+	return top*(1-fy)+bottom*fy <= float64(bm.threshold)
+}
+
+// luminanceAt returns the luminance of the pixel at column 'x' and row 'y' of 'bm'. Pixels outside of 'bm' are
+// white.
+func (bm *bitmap) luminanceAt(x int, y int) float64 {
+
+	if x < 0 || y < 0 || x >= bm.width || y >= bm.height {
+		return 255
+	}
+
+	return float64(bm.luminance[y*bm.width+x])
+}
@@ -0,0 +1,71 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// SYMBOLOGY_DATAMATRIX is the symbology of Data Matrix barcodes, used by some carriers and rail/air codeshares.
+const SYMBOLOGY_DATAMATRIX string = "datamatrix"
+
+// DataMatrixBarcode implements the `bcbp.Barcode` interface for Data Matrix barcodes.
+type DataMatrixBarcode struct{}
+
+func init() {
+	ctx := context.Background()
+	bcbp.RegisterBarcode(ctx, "datamatrix", NewDataMatrixBarcode)
+}
+
+// NewDataMatrixBarcode returns a new `DataMatrixBarcode` instance configured by 'uri' which is expected to take
+// the form of:
+//
+//	datamatrix://
+func NewDataMatrixBarcode(ctx context.Context, uri string) (bcbp.Barcode, error) {
+	bc := &DataMatrixBarcode{}
+	return bc, nil
+}
+
+// Encode is not implemented yet and returns an error.
+func (bc *DataMatrixBarcode) Encode(b *bcbp.BCBP, wr io.Writer) error {
+	return fmt.Errorf("Encoding Data Matrix barcodes is not supported")
+}
+
+// Decode decodes the Data Matrix symbol in the image data contained in 'r' and parses its text as a BCBP string, in
+// lenient mode.
+func (bc *DataMatrixBarcode) Decode(r io.Reader) (*bcbp.BCBP, error) {
+
+	s, err := bc.DecodeSymbol(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSymbol(s)
+}
+
+// DecodeSymbol decodes the Data Matrix symbol in the PNG, JPEG or GIF image data contained in 'r'. The symbol may
+// be at any orientation.
+func (bc *DataMatrixBarcode) DecodeSymbol(r io.Reader) (*Symbol, error) {
+	return decodeSymbol(bc, r)
+}
+
+func (bc *DataMatrixBarcode) decodeImage(im image.Image) (*Symbol, error) {
+
+	text, err := readZXing(datamatrix.NewDataMatrixReader(), im)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Data Matrix symbol, %w", err)
+	}
+
+	s := &Symbol{
+		Symbology: SYMBOLOGY_DATAMATRIX,
+		Text:      text,
+	}
+
+	return s, nil
+}
@@ -0,0 +1,13 @@
+// Package barcode provides pure Go implementations of the sfomuseum/go-bcbp `Barcode` interface, registered
+// with `bcbp.RegisterBarcode`, for reading the BCBP string encoded in an image of a boarding pass barcode and for
+// rendering BCBP strings as barcodes, in PNG images or SVG documents.
+//
+// Importing this package registers the following schemes:
+//
+//   - pdf417:// reads and writes PDF417 symbols.
+//   - aztec:// reads Aztec symbols, using github.com/makiuchi-d/gozxing, and writes them, using
+//     github.com/boombuler/barcode.
+//   - qr:// reads QR codes, using github.com/makiuchi-d/gozxing.
+//   - datamatrix:// reads Data Matrix symbols, using github.com/makiuchi-d/gozxing.
+//   - auto:// tries each of the other registered schemes in turn.
+package barcode
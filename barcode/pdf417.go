@@ -0,0 +1,167 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// SYMBOLOGY_PDF417 is the symbology of PDF417 barcodes, the symbology used by printed boarding passes.
+const SYMBOLOGY_PDF417 string = "pdf417"
+
+// PDF417_MODULE_SIZE is the default width and height, in pixels, of each module of a rendered PDF417 symbol.
+const PDF417_MODULE_SIZE int = 2
+
+// PDF417_QUIET_ZONE is the default width, in modules, of the blank margin surrounding a rendered PDF417 symbol.
+const PDF417_QUIET_ZONE int = 2
+
+// PDF417Barcode implements the `bcbp.Barcode` interface for PDF417 barcodes.
+type PDF417Barcode struct {
+	level  int
+	render *renderOptions
+}
+
+func init() {
+	ctx := context.Background()
+	bcbp.RegisterBarcode(ctx, "pdf417", NewPDF417Barcode)
+}
+
+// NewPDF417Barcode returns a new `PDF417Barcode` instance configured by 'uri' which is expected to take the form
+// of:
+//
+//	pdf417://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+//   - `error_correction` The error correction level (0-8) of encoded symbols. Default is the level recommended
+//     for the amount of data.
+//   - `module_size` The width and height, in pixels, of each module of encoded symbols. Default is
+//     PDF417_MODULE_SIZE.
+//   - `quiet_zone` The width, in modules, of the blank margin surrounding encoded symbols. Default is
+//     PDF417_QUIET_ZONE.
+func NewPDF417Barcode(ctx context.Context, uri string) (bcbp.Barcode, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	level, err := intParameter(q, "error_correction", -1, 0, 8)
+
+	if err != nil {
+		return nil, err
+	}
+
+	render, err := newRenderOptions(q, PDF417_MODULE_SIZE, PDF417_QUIET_ZONE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bc := &PDF417Barcode{
+		level:  level,
+		render: render,
+	}
+
+	return bc, nil
+}
+
+// Encode encodes 'b' as a BCBP string, with its legs concatenated as described by Resolution 792, and writes it
+// to 'wr' as a PDF417 symbol in a PNG image.
+func (bc *PDF417Barcode) Encode(b *bcbp.BCBP, wr io.Writer) error {
+
+	text, err := marshalSymbol(b)
+
+	if err != nil {
+		return fmt.Errorf("Failed to marshal BCBP, %w", err)
+	}
+
+	return bc.EncodeSymbol(text, wr)
+}
+
+// EncodeSymbol writes 'text' to 'wr' as a PDF417 symbol in a PNG image.
+func (bc *PDF417Barcode) EncodeSymbol(text string, wr io.Writer) error {
+
+	m, err := bc.EncodeMatrix(text)
+
+	if err != nil {
+		return err
+	}
+
+	return writePNG(m, bc.render, wr)
+}
+
+// EncodeSVG writes 'text' to 'wr' as a PDF417 symbol in an SVG document using 'opts'.
+func (bc *PDF417Barcode) EncodeSVG(text string, opts *SVGOptions, wr io.Writer) error {
+
+	m, err := bc.EncodeMatrix(text)
+
+	if err != nil {
+		return err
+	}
+
+	return writeSVG(m, bc.render, opts, wr)
+}
+
+// EncodeMatrix encodes 'text' as the modules of a PDF417 symbol. Each row of the symbol is PDF417_ROW_HEIGHT
+// modules high and the number of columns is chosen so that the symbol is roughly PDF417_ASPECT_RATIO times as wide
+// as it is high.
+func (bc *PDF417Barcode) EncodeMatrix(text string) (*Matrix, error) {
+
+	m, err := encodePDF417([]byte(text), bc.level)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode PDF417 symbol, %w", err)
+	}
+
+	return m, nil
+}
+
+// Decode decodes the PDF417 symbol in the image data contained in 'r' and parses its text as a BCBP string, in
+// lenient mode.
+func (bc *PDF417Barcode) Decode(r io.Reader) (*bcbp.BCBP, error) {
+
+	s, err := bc.DecodeSymbol(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSymbol(s)
+}
+
+// DecodeSymbol decodes the PDF417 symbol in the PNG, JPEG or GIF image data contained in 'r'. The symbol may be
+// rotated by any angle and scaled so long as each module is at least about one and a half pixels wide. Damaged
+// codewords are corrected so long as twice the number of misread codewords plus the number of unreadable codewords
+// is no more than the number of the symbol's error correction codewords.
+func (bc *PDF417Barcode) DecodeSymbol(r io.Reader) (*Symbol, error) {
+	return decodeSymbol(bc, r)
+}
+
+func (bc *PDF417Barcode) decodeImage(im image.Image) (*Symbol, error) {
+
+	codewords, err := readPDF417(newBitmap(im))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read PDF417 symbol, %w", err)
+	}
+
+	data, err := decodePDF417Data(codewords[1:])
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode PDF417 symbol, %w", err)
+	}
+
+	s := &Symbol{
+		Symbology: SYMBOLOGY_PDF417,
+		Text:      string(data),
+	}
+
+	return s, nil
+}
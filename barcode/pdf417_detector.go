@@ -0,0 +1,312 @@
+package barcode
+
+import (
+	"math"
+	"slices"
+)
+
+// PDF417 symbols which are not aligned with the edges of an image, because they have been photographed or scanned
+// at an angle, are located using their start and stop patterns. Every scanline which crosses the start pattern
+// yields a point on the left edge of the symbol and every scanline which crosses the stop pattern a point on its
+// right edge. A line is fitted to each set of points, the top and bottom of each edge are found by following the
+// widest bar of each pattern and the quadrilateral bounded by the four corners is resampled as an aligned bitmap
+// which is read one scanline at a time, see readPDF417Scanlines.
+
+// PDF417_SAMPLES_PER_MODULE is the width and height, in pixels, of each module of a resampled PDF417 symbol.
+const PDF417_SAMPLES_PER_MODULE int = 4
+
+// pdf417EdgePoint is a point on the left edge of a start pattern, or the right edge of a stop pattern, found by a
+// scanline.
+type pdf417EdgePoint struct {
+	x float64
+	y float64
+	// The width, in pixels, of each module of the pattern measured along the scanline.
+	unit float64
+}
+
+// pdf417Edge is the left edge of a start pattern, or the right edge of a stop pattern, of a PDF417 symbol.
+type pdf417Edge struct {
+	// The top and bottom of the edge.
+	top    pdf417Point
+	bottom pdf417Point
+	// The width, in pixels, of each module perpendicular to the edge.
+	unit float64
+}
+
+// pdf417Point is a point, measured in pixels from the top left corner, in an image.
+type pdf417Point struct {
+	x float64
+	y float64
+}
+
+// findPDF417Edges returns the points on the left edge of the start pattern and the right edge of the stop
+// pattern of a PDF417 symbol found by each scanline of 'bm'.
+func findPDF417Edges(bm *bitmap) ([]*pdf417EdgePoint, []*pdf417EdgePoint) {
+
+	starts := make([]*pdf417EdgePoint, 0)
+	stops := make([]*pdf417EdgePoint, 0)
+
+	for y := 0; y < bm.height; y++ {
+
+		row := bm.row(y)
+
+		for x := 0; x < len(row); x++ {
+
+			if !row[x] || (x > 0 && row[x-1]) {
+				continue
+			}
+
+			runs := runsAt(row, x, len(pdf417StopRuns))
+
+			if runs == nil {
+				runs = runsAt(row, x, len(pdf417StartRuns))
+			}
+
+			if runs == nil {
+				break
+			}
+
+			start := runs[:len(pdf417StartRuns)]
+
+			if matchRuns(start, pdf417StartRuns, 17) {
+				starts = append(starts, &pdf417EdgePoint{float64(x), float64(y) + 0.5, float64(sum(start)) / 17})
+			}
+
+			if len(runs) == len(pdf417StopRuns) && matchRuns(runs, pdf417StopRuns, 18) {
+				stops = append(stops, &pdf417EdgePoint{float64(x + sum(runs)), float64(y) + 0.5, float64(sum(runs)) / 18})
+			}
+		}
+	}
+
+	return starts, stops
+}
+
+// fitPDF417Edge returns the line, x = a * y + b, which passes through the most points in 'points' along with those
+// points, or false if there are too few points to fit a line.
+func fitPDF417Edge(points []*pdf417EdgePoint) (float64, float64, []*pdf417EdgePoint, bool) {
+
+	if len(points) < 3 {
+		return 0, 0, nil, false
+	}
+
+	units := make([]float64, len(points))
+
+	for i, p := range points {
+		units[i] = p.unit
+	}
+
+	slices.Sort(units)
+	tolerance := max(1.5, units[len(units)/2])
+
+	// Candidate lines are drawn through pairs of points half the list apart, which for a single symbol means
+	// pairs of points from its top and bottom halves
+
+	var best []*pdf417EdgePoint
+
+	half := len(points) / 2
+	step := max(1, half/50)
+
+	for i := 0; i < half; i += step {
+
+		p := points[i]
+		q := points[i+half]
+
+		if q.y == p.y {
+			continue
+		}
+
+		a := (q.x - p.x) / (q.y - p.y)
+		b := p.x - a*p.y
+
+		inliers := edgeInliers(points, a, b, tolerance)
+
+		if len(inliers) > len(best) {
+			best = inliers
+		}
+	}
+
+	if len(best) < 3 {
+		return 0, 0, nil, false
+	}
+
+	// Refine the line using a least squares fit of its points
+
+	n := float64(len(best))
+	sum_y := 0.0
+	sum_x := 0.0
+	sum_yy := 0.0
+	sum_xy := 0.0
+
+	for _, p := range best {
+		sum_y += p.y
+		sum_x += p.x
+		sum_yy += p.y * p.y
+		sum_xy += p.x * p.y
+	}
+
+	denominator := n*sum_yy - sum_y*sum_y
+
+	if denominator == 0 {
+		return 0, 0, nil, false
+	}
+
+	a := (n*sum_xy - sum_x*sum_y) / denominator
+	b := (sum_x - a*sum_y) / n
+
+	return a, b, edgeInliers(points, a, b, tolerance), true
+}
+
+// edgeInliers returns the points in 'points' within 'tolerance' pixels, horizontally, of the line x = a * y + b.
+func edgeInliers(points []*pdf417EdgePoint, a float64, b float64, tolerance float64) []*pdf417EdgePoint {
+
+	inliers := make([]*pdf417EdgePoint, 0)
+
+	for _, p := range points {
+
+		if math.Abs(a*p.y+b-p.x) <= tolerance {
+			inliers = append(inliers, p)
+		}
+	}
+
+	return inliers
+}
+
+// findPDF417Edge returns the edge of a PDF417 symbol in 'bm' through 'points'. The top and bottom of the edge are
+// found by following the centre of the bar which is 'offset' modules from the edge until it ends. A negative
+// 'offset' is to the left of the edge. Since the rows of a symbol are perpendicular to its edges the corners are
+// then 'offset' modules back along the rows.
+func findPDF417Edge(bm *bitmap, points []*pdf417EdgePoint, offset float64) (*pdf417Edge, bool) {
+
+	a, b, inliers, ok := fitPDF417Edge(points)
+
+	if !ok {
+		return nil, false
+	}
+
+	// 'down' is the direction of the edge and 'across' the direction of the rows of the symbol
+
+	length := math.Hypot(a, 1)
+	down := pdf417Point{a / length, 1 / length}
+	across := pdf417Point{down.y, -down.x}
+
+	units := make([]float64, len(inliers))
+
+	for i, p := range inliers {
+		units[i] = p.unit
+	}
+
+	slices.Sort(units)
+	unit := units[len(units)/2] * down.y
+
+	mid := inliers[len(inliers)/2]
+	origin := pdf417Point{a*mid.y + b + across.x*offset*unit, mid.y + across.y*offset*unit}
+
+	top := followPDF417Bar(bm, origin, pdf417Point{-down.x, -down.y}, unit)
+	bottom := followPDF417Bar(bm, origin, down, unit)
+
+	e := &pdf417Edge{
+		top:    pdf417Point{top.x - across.x*offset*unit, top.y - across.y*offset*unit},
+		bottom: pdf417Point{bottom.x - across.x*offset*unit, bottom.y - across.y*offset*unit},
+		unit:   unit,
+	}
+
+	return e, true
+}
+
+// followPDF417Bar returns the last dark point of 'bm' found by stepping from 'origin' in the direction 'dir' until
+// there are at least two modules, each 'unit' pixels wide, of consecutive light points.
+func followPDF417Bar(bm *bitmap, origin pdf417Point, dir pdf417Point, unit float64) pdf417Point {
+
+	last := origin
+	gap := 0.0
+	step := 0.5
+
+	for d := 0.0; gap < unit*2; d += step {
+
+		p := pdf417Point{origin.x + dir.x*d, origin.y + dir.y*d}
+
+		if p.x < 0 || p.y < 0 || p.x >= float64(bm.width) || p.y >= float64(bm.height) {
+			break
+		}
+
+		if bm.sample(p.x, p.y) {
+			last = p
+			gap = 0
+		} else {
+			gap += step
+		}
+	}
+
+	return last
+}
+
+// locatePDF417 returns the PDF417 symbol in 'bm' resampled as a bitmap aligned with its rows, surrounded by a quiet
+// zone, or false if its start and stop patterns could not be found.
+func locatePDF417(bm *bitmap) (*bitmap, bool) {
+
+	starts, stops := findPDF417Edges(bm)
+
+	// The widest bar of the start pattern is the first eight modules and of the stop pattern the first seven
+
+	left, ok := findPDF417Edge(bm, starts, 4)
+
+	if !ok {
+		return nil, false
+	}
+
+	right, ok := findPDF417Edge(bm, stops, -14.5)
+
+	if !ok {
+		return nil, false
+	}
+
+	unit := (left.unit + right.unit) / 2
+
+	width := math.Hypot((right.top.x+right.bottom.x-left.top.x-left.bottom.x)/2, (right.top.y+right.bottom.y-left.top.y-left.bottom.y)/2) / unit
+	height := math.Hypot((left.bottom.x+right.bottom.x-left.top.x-right.top.x)/2, (left.bottom.y+right.bottom.y-left.top.y-right.top.y)/2) / unit
+
+	// A symbol has at least one data column and at most 30 data columns and 90 rows, each several modules high
+
+	if width < 17*5 || width > 17*35 || height < 3 || height > 90*10 {
+		return nil, false
+	}
+
+	return resamplePDF417(bm, left, right, int(math.Round(width)), int(math.Round(height))), true
+}
+
+// resamplePDF417 returns the quadrilateral of 'bm' bounded by the edges 'left' and 'right', which is 'width' by
+// 'height' modules, as a bitmap with PDF417_SAMPLES_PER_MODULE pixels per module surrounded by a two module quiet
+// zone. Only the bits of the bitmap are set, it cannot be resampled or rotated.
+func resamplePDF417(bm *bitmap, left *pdf417Edge, right *pdf417Edge, width int, height int) *bitmap {
+
+	const quiet_zone = 2
+
+	n := PDF417_SAMPLES_PER_MODULE
+
+	r := &bitmap{
+		width:     (width + quiet_zone*2) * n,
+		height:    (height + quiet_zone*2) * n,
+		threshold: bm.threshold,
+	}
+
+	r.bits = make([]bool, r.width*r.height)
+
+	for y := 0; y < r.height; y++ {
+
+		// The position of the centre of each sample as a fraction of the height (or width) of the symbol
+
+		v := ((float64(y)+0.5)/float64(n) - quiet_zone) / float64(height)
+
+		l := pdf417Point{left.top.x + (left.bottom.x-left.top.x)*v, left.top.y + (left.bottom.y-left.top.y)*v}
+		rt := pdf417Point{right.top.x + (right.bottom.x-right.top.x)*v, right.top.y + (right.bottom.y-right.top.y)*v}
+
+		for x := 0; x < r.width; x++ {
+
+			u := ((float64(x)+0.5)/float64(n) - quiet_zone) / float64(width)
+
+			r.bits[y*r.width+x] = bm.sample(l.x+(rt.x-l.x)*u, l.y+(rt.y-l.y)*u)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,373 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// PDF417 error correction is Reed-Solomon over the prime field GF(929) with the generator 3. The decoder follows
+// the approach of ZXing: syndromes are computed at 3^1 ... 3^k, the error locator and evaluator polynomials are
+// found using the extended Euclidean algorithm and errors are located using a Chien search. Unlike ZXing, known
+// erasures are folded in to the syndromes so that up to k of them can be corrected.
+
+// PDF417_MODULUS is the size of the field PDF417 codewords (and error correction) are defined over.
+const PDF417_MODULUS int = 929
+
+var gf929Exp [PDF417_MODULUS]int
+var gf929Log [PDF417_MODULUS]int
+
+func init() {
+
+	x := 1
+
+	for i := 0; i < PDF417_MODULUS; i++ {
+		gf929Exp[i] = x
+		x = (x * 3) % PDF417_MODULUS
+	}
+
+	for i := 0; i < PDF417_MODULUS-1; i++ {
+		gf929Log[gf929Exp[i]] = i
+	}
+}
+
+func gfAdd(a int, b int) int {
+	return (a + b) % PDF417_MODULUS
+}
+
+func gfSubtract(a int, b int) int {
+	return (PDF417_MODULUS + a - b) % PDF417_MODULUS
+}
+
+func gfMultiply(a int, b int) int {
+
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gf929Exp[(gf929Log[a]+gf929Log[b])%(PDF417_MODULUS-1)]
+}
+
+func gfInverse(a int) int {
+	return gf929Exp[PDF417_MODULUS-1-gf929Log[a]]
+}
+
+// gfPoly is a polynomial over GF(929), coefficients are ordered from the highest degree term to the constant.
+type gfPoly []int
+
+func newGFPoly(coefficients []int) gfPoly {
+
+	for len(coefficients) > 1 && coefficients[0] == 0 {
+		coefficients = coefficients[1:]
+	}
+
+	if len(coefficients) == 0 {
+		return gfPoly{0}
+	}
+
+	return gfPoly(coefficients)
+}
+
+func gfMonomial(degree int, coefficient int) gfPoly {
+
+	if coefficient == 0 {
+		return gfPoly{0}
+	}
+
+	p := make([]int, degree+1)
+	p[0] = coefficient
+
+	return gfPoly(p)
+}
+
+func (p gfPoly) degree() int {
+	return len(p) - 1
+}
+
+func (p gfPoly) isZero() bool {
+	return p[0] == 0
+}
+
+func (p gfPoly) coefficient(degree int) int {
+	return p[len(p)-1-degree]
+}
+
+func (p gfPoly) evaluateAt(a int) int {
+
+	result := 0
+
+	for _, c := range p {
+		result = gfAdd(gfMultiply(a, result), c)
+	}
+
+	return result
+}
+
+func (p gfPoly) add(other gfPoly) gfPoly {
+
+	smaller := p
+	larger := other
+
+	if len(smaller) > len(larger) {
+		smaller, larger = larger, smaller
+	}
+
+	sum := make([]int, len(larger))
+	diff := len(larger) - len(smaller)
+
+	copy(sum, larger[:diff])
+
+	for i := diff; i < len(larger); i++ {
+		sum[i] = gfAdd(smaller[i-diff], larger[i])
+	}
+
+	return newGFPoly(sum)
+}
+
+func (p gfPoly) negative() gfPoly {
+
+	n := make([]int, len(p))
+
+	for i, c := range p {
+		n[i] = gfSubtract(0, c)
+	}
+
+	return newGFPoly(n)
+}
+
+func (p gfPoly) subtract(other gfPoly) gfPoly {
+	return p.add(other.negative())
+}
+
+func (p gfPoly) multiply(other gfPoly) gfPoly {
+
+	if p.isZero() || other.isZero() {
+		return gfPoly{0}
+	}
+
+	product := make([]int, len(p)+len(other)-1)
+
+	for i, a := range p {
+
+		for j, b := range other {
+			product[i+j] = gfAdd(product[i+j], gfMultiply(a, b))
+		}
+	}
+
+	return newGFPoly(product)
+}
+
+func (p gfPoly) scale(scalar int) gfPoly {
+
+	s := make([]int, len(p))
+
+	for i, c := range p {
+		s[i] = gfMultiply(c, scalar)
+	}
+
+	return newGFPoly(s)
+}
+
+func (p gfPoly) multiplyByMonomial(degree int, coefficient int) gfPoly {
+
+	if coefficient == 0 {
+		return gfPoly{0}
+	}
+
+	product := make([]int, len(p)+degree)
+
+	for i, c := range p {
+		product[i] = gfMultiply(c, coefficient)
+	}
+
+	return newGFPoly(product)
+}
+
+// correctPDF417 corrects, in place, errors in 'codewords', the data codewords of a PDF417 symbol followed by its
+// 'ec_count' error correction codewords. The positions in 'erasures' are codewords which are known to be wrong,
+// because they could not be read, and any number of errors and erasures can be corrected so long as twice the
+// number of errors plus the number of erasures is no more than 'ec_count'. It returns the number of codewords
+// corrected.
+func correctPDF417(codewords []int, ec_count int, erasures []int) (int, error) {
+
+	syndromes, has_errors := pdf417Syndromes(codewords, ec_count)
+
+	if !has_errors {
+		return 0, nil
+	}
+
+	if len(erasures) > ec_count {
+		return 0, fmt.Errorf("Too many errors to correct")
+	}
+
+	// The erasure locator has a root at the inverse of the location of each erasure. Multiplying the syndromes by
+	// it leaves a key equation for the errors alone (Forney syndromes), which is solved as usual and whose
+	// solution is multiplied by the erasure locator to locate every error and erasure.
+
+	erasure_locator := gfPoly{1}
+
+	for _, position := range erasures {
+
+		if position < 0 || position >= len(codewords) {
+			return 0, fmt.Errorf("Erasure location %d is outside of the symbol", position)
+		}
+
+		loc := gf929Exp[len(codewords)-1-position]
+		erasure_locator = erasure_locator.multiply(gfPoly{gfSubtract(0, loc), 1})
+	}
+
+	forney := newGFPoly(syndromes).multiply(erasure_locator)
+
+	if forney.degree() >= ec_count {
+		forney = newGFPoly(forney[len(forney)-ec_count:])
+	}
+
+	sigma, omega, err := pdf417Euclidean(gfMonomial(ec_count, 1), forney, ec_count+len(erasures))
+
+	if err != nil {
+		return 0, err
+	}
+
+	sigma = sigma.multiply(erasure_locator)
+
+	locations, err := pdf417ErrorLocations(sigma)
+
+	if err != nil {
+		return 0, err
+	}
+
+	magnitudes := pdf417ErrorMagnitudes(omega, sigma, locations)
+
+	for i, loc := range locations {
+
+		position := len(codewords) - 1 - gf929Log[loc]
+
+		if position < 0 {
+			return 0, fmt.Errorf("Error location %d is outside of the symbol", position)
+		}
+
+		codewords[position] = gfSubtract(codewords[position], magnitudes[i])
+	}
+
+	// More errors than can be corrected may be "corrected" to a different, invalid, set of codewords
+
+	_, has_errors = pdf417Syndromes(codewords, ec_count)
+
+	if has_errors {
+		return 0, fmt.Errorf("Too many errors to correct")
+	}
+
+	return len(locations), nil
+}
+
+// pdf417Syndromes returns the syndromes of 'codewords', which are followed by 'ec_count' error correction
+// codewords, as the coefficients of a polynomial and whether any of them are non-zero.
+func pdf417Syndromes(codewords []int, ec_count int) ([]int, bool) {
+
+	received := newGFPoly(append([]int{}, codewords...))
+	syndromes := make([]int, ec_count)
+	has_errors := false
+
+	for i := 1; i <= ec_count; i++ {
+
+		eval := received.evaluateAt(gf929Exp[i])
+		syndromes[ec_count-i] = eval
+
+		if eval != 0 {
+			has_errors = true
+		}
+	}
+
+	return syndromes, has_errors
+}
+
+func pdf417Euclidean(a gfPoly, b gfPoly, r int) (gfPoly, gfPoly, error) {
+
+	if a.degree() < b.degree() {
+		a, b = b, a
+	}
+
+	r_last := a
+	r_cur := b
+	t_last := gfPoly{0}
+	t_cur := gfPoly{1}
+
+	for r_cur.degree() >= r/2 {
+
+		r_last_last := r_last
+		t_last_last := t_last
+		r_last = r_cur
+		t_last = t_cur
+
+		if r_last.isZero() {
+			return nil, nil, fmt.Errorf("Too many errors to correct")
+		}
+
+		r_cur = r_last_last
+		q := gfPoly{0}
+
+		inverse := gfInverse(r_last.coefficient(r_last.degree()))
+
+		for r_cur.degree() >= r_last.degree() && !r_cur.isZero() {
+
+			degree_diff := r_cur.degree() - r_last.degree()
+			scale := gfMultiply(r_cur.coefficient(r_cur.degree()), inverse)
+
+			q = q.add(gfMonomial(degree_diff, scale))
+			r_cur = r_cur.subtract(r_last.multiplyByMonomial(degree_diff, scale))
+		}
+
+		t_cur = q.multiply(t_last).subtract(t_last_last).negative()
+	}
+
+	sigma_at_zero := t_cur.coefficient(0)
+
+	if sigma_at_zero == 0 {
+		return nil, nil, fmt.Errorf("Too many errors to correct")
+	}
+
+	inverse := gfInverse(sigma_at_zero)
+
+	return t_cur.scale(inverse), r_cur.scale(inverse), nil
+}
+
+func pdf417ErrorLocations(locator gfPoly) ([]int, error) {
+
+	count := locator.degree()
+	locations := make([]int, 0, count)
+
+	for i := 1; i < PDF417_MODULUS && len(locations) < count; i++ {
+
+		if locator.evaluateAt(i) == 0 {
+			locations = append(locations, gfInverse(i))
+		}
+	}
+
+	if len(locations) != count {
+		return nil, fmt.Errorf("Too many errors to correct")
+	}
+
+	return locations, nil
+}
+
+func pdf417ErrorMagnitudes(evaluator gfPoly, locator gfPoly, locations []int) []int {
+
+	degree := locator.degree()
+	derivative := make([]int, degree)
+
+	for i := 1; i <= degree; i++ {
+		derivative[degree-i] = gfMultiply(i, locator.coefficient(i))
+	}
+
+	formal_derivative := newGFPoly(derivative)
+	magnitudes := make([]int, len(locations))
+
+	for i, loc := range locations {
+
+		xi_inverse := gfInverse(loc)
+		numerator := gfSubtract(0, evaluator.evaluateAt(xi_inverse))
+		denominator := gfInverse(formal_derivative.evaluateAt(xi_inverse))
+
+		magnitudes[i] = gfMultiply(numerator, denominator)
+	}
+
+	return magnitudes
+}
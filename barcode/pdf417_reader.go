@@ -0,0 +1,575 @@
+package barcode
+
+import (
+	"fmt"
+	"math"
+)
+
+// PDF417 symbols are read one scanline at a time. Each scanline that crosses a complete row yields the row
+// indicators and data codewords of that row, the row number is derived from the left (or right) row indicator
+// and the value of each codeword is decided by a majority vote across every scanline which crossed it. A symbol is
+// first read directly from the image, which works when it is aligned with the edges of the image as it is in
+// screenshots, and if that fails it is located and resampled, see locatePDF417. Codewords which no scanline could
+// read are treated as erasures when correcting errors. Both are tried in each of the four orientations.
+
+// pdf417StartRuns are the widths, in modules, of the bars and spaces of the start pattern.
+var pdf417StartRuns = []int{8, 1, 1, 1, 1, 1, 1, 3}
+
+// pdf417StopRuns are the widths, in modules, of the bars and spaces of the stop pattern.
+var pdf417StopRuns = []int{7, 1, 1, 3, 1, 1, 1, 2, 1}
+
+// pdf417Codeword is the value and cluster of a codeword.
+type pdf417Codeword struct {
+	value   int
+	cluster int
+}
+
+// pdf417Patterns maps the 17 module pattern of every codeword, in every cluster, to its value and cluster.
+var pdf417Patterns map[int]*pdf417Codeword
+
+func init() {
+
+	pdf417Patterns = make(map[int]*pdf417Codeword)
+
+	for cluster, table := range pdf417Codewords {
+
+		for value, pattern := range table {
+			pdf417Patterns[pattern] = &pdf417Codeword{value, cluster}
+		}
+	}
+}
+
+// pdf417Scanline is the row of a PDF417 symbol crossed by a single scanline.
+type pdf417Scanline struct {
+	// The left row indicator, followed by the data codewords, followed by the right row indicator. Codewords which
+	// could not be read are nil.
+	codewords []*pdf417Codeword
+	// The row number, or -1 if neither row indicator could be read.
+	row int
+}
+
+// pdf417Metadata are the dimensions and error correction level of a PDF417 symbol, as voted for by its row
+// indicators.
+type pdf417Metadata struct {
+	rows    int
+	columns int
+	level   int
+}
+
+// readPDF417 returns the corrected data codewords, starting with the symbol length descriptor and excluding any
+// error correction codewords, of the PDF417 symbol in 'bm' trying each of the four orientations of 'bm' in turn.
+func readPDF417(bm *bitmap) ([]int, error) {
+
+	var err error
+
+	for i := 0; i < 4; i++ {
+
+		if i > 0 {
+			bm = bm.rotate()
+		}
+
+		var codewords []int
+
+		codewords, err = readPDF417Orientation(bm)
+
+		if err == nil {
+			return codewords, nil
+		}
+	}
+
+	return nil, err
+}
+
+// readPDF417Orientation returns the corrected data codewords of the PDF417 symbol in 'bm', reading it directly
+// and then, if that fails, once it has been located and resampled.
+func readPDF417Orientation(bm *bitmap) ([]int, error) {
+
+	codewords, err := readPDF417Bitmap(bm)
+
+	if err == nil {
+		return codewords, nil
+	}
+
+	located, ok := locatePDF417(bm)
+
+	if !ok {
+		return nil, err
+	}
+
+	return readPDF417Bitmap(located)
+}
+
+// readPDF417Bitmap returns the corrected data codewords of the PDF417 symbol whose rows are aligned with the
+// scanlines of 'bm'.
+func readPDF417Bitmap(bm *bitmap) ([]int, error) {
+
+	codewords, erasures, md, err := readPDF417Scanlines(bm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ec_count := 1 << (md.level + 1)
+
+	if ec_count >= len(codewords) {
+		return nil, fmt.Errorf("Invalid PDF417 symbol, %d error correction codewords for %d codewords", ec_count, len(codewords))
+	}
+
+	_, err = correctPDF417(codewords, ec_count, erasures)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to correct PDF417 symbol, %w", err)
+	}
+
+	// The symbol length descriptor counts itself, the data codewords and any padding but not error correction
+
+	length := codewords[0]
+
+	if length < 1 || length > len(codewords)-ec_count {
+		return nil, fmt.Errorf("Invalid PDF417 symbol length descriptor %d", length)
+	}
+
+	return codewords[:length], nil
+}
+
+// readPDF417Scanlines returns the codewords, in reading order, of the PDF417 symbol whose rows are aligned with
+// the scanlines of 'bm' along with the positions of the codewords which could not be read and the symbol's
+// metadata.
+func readPDF417Scanlines(bm *bitmap) ([]int, []int, *pdf417Metadata, error) {
+
+	scanlines := make([]*pdf417Scanline, 0)
+
+	for y := 0; y < bm.height; y++ {
+
+		codewords := scanPDF417Row(bm.row(y))
+
+		if codewords == nil {
+			continue
+		}
+
+		s := &pdf417Scanline{
+			codewords: codewords,
+			row:       pdf417RowNumber(codewords),
+		}
+
+		scanlines = append(scanlines, s)
+	}
+
+	if len(scanlines) == 0 {
+		return nil, nil, nil, fmt.Errorf("No PDF417 rows found")
+	}
+
+	md, err := pdf417VoteMetadata(scanlines)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	votes := make([]map[int]int, md.rows*md.columns)
+
+	for _, s := range scanlines {
+
+		if s.row < 0 || s.row >= md.rows || len(s.codewords) != md.columns+2 {
+			continue
+		}
+
+		for col, cw := range s.codewords[1 : md.columns+1] {
+
+			if cw == nil || cw.cluster != s.row%3 {
+				continue
+			}
+
+			idx := s.row*md.columns + col
+
+			if votes[idx] == nil {
+				votes[idx] = make(map[int]int)
+			}
+
+			votes[idx][cw.value] += 1
+		}
+	}
+
+	codewords := make([]int, len(votes))
+	erasures := make([]int, 0)
+
+	for idx, v := range votes {
+
+		if len(v) == 0 {
+			erasures = append(erasures, idx)
+		}
+
+		codewords[idx] = mostVoted(v, 0)
+	}
+
+	return codewords, erasures, md, nil
+}
+
+// pdf417RowNumber returns the row number encoded by the row indicators in 'codewords', or -1 if neither row
+// indicator could be read or the row number is inconsistent with the other codewords. A scanline which crosses
+// from one row in to the next, as they do when a symbol is skewed, may read the row indicators of different rows.
+func pdf417RowNumber(codewords []*pdf417Codeword) int {
+
+	row := -1
+
+	for _, cw := range []*pdf417Codeword{codewords[0], codewords[len(codewords)-1]} {
+
+		if cw == nil {
+			continue
+		}
+
+		n := (cw.value/30)*3 + cw.cluster
+
+		if row != -1 && n != row {
+			return -1
+		}
+
+		row = n
+	}
+
+	if row == -1 {
+		return -1
+	}
+
+	// Every codeword of a row is in the same cluster so most of those which could be read should agree
+
+	agree := 0
+	disagree := 0
+
+	for _, cw := range codewords {
+
+		switch {
+		case cw == nil:
+			// pass
+		case cw.cluster == row%3:
+			agree += 1
+		default:
+			disagree += 1
+		}
+	}
+
+	if disagree >= agree {
+		return -1
+	}
+
+	return row
+}
+
+// pdf417VoteMetadata returns the dimensions and error correction level of a PDF417 symbol from the row indicators
+// in 'scanlines'.
+func pdf417VoteMetadata(scanlines []*pdf417Scanline) (*pdf417Metadata, error) {
+
+	upper_left := make(map[int]int)
+	upper_right := make(map[int]int)
+	lower := make(map[int]int)
+	columns := make(map[int]int)
+	levels := make(map[int]int)
+
+	max_row := -1
+
+	for _, s := range scanlines {
+
+		if s.row < 0 {
+			continue
+		}
+
+		max_row = max(max_row, s.row)
+
+		left := s.codewords[0]
+		right := s.codewords[len(s.codewords)-1]
+
+		// The three clusters of the left and right row indicators encode the metadata in a different order
+
+		if left != nil {
+
+			info := left.value % 30
+
+			switch left.cluster {
+			case 0:
+				upper_left[info] += 1
+			case 1:
+				levels[info/3] += 1
+				lower[info%3] += 1
+			case 2:
+				columns[info+1] += 1
+			}
+		}
+
+		if right != nil {
+
+			info := right.value % 30
+
+			switch right.cluster {
+			case 0:
+				columns[info+1] += 1
+			case 1:
+				upper_right[info] += 1
+			case 2:
+				levels[info/3] += 1
+				lower[info%3] += 1
+			}
+		}
+	}
+
+	if max_row < 0 || len(columns) == 0 || len(levels) == 0 {
+		return nil, fmt.Errorf("Failed to read PDF417 row indicators")
+	}
+
+	// Some encoders compute the left row indicator of the first cluster incorrectly so the right row indicator is
+	// preferred when both are present. The highest row number read is only used if neither is, since a single
+	// misread row indicator can produce any row number.
+
+	upper := mostVoted(upper_right, -1)
+
+	if upper == -1 {
+		upper = mostVoted(upper_left, -1)
+	}
+
+	rows := max_row + 1
+
+	if upper != -1 && len(lower) > 0 {
+		rows = upper*3 + mostVoted(lower, 0) + 1
+	}
+
+	md := &pdf417Metadata{
+		rows:    rows,
+		columns: mostVoted(columns, 0),
+		level:   mostVoted(levels, 0),
+	}
+
+	if md.columns < 1 || md.rows < 1 {
+		return nil, fmt.Errorf("Invalid PDF417 dimensions, %d rows and %d columns", md.rows, md.columns)
+	}
+
+	return md, nil
+}
+
+// mostVoted returns the key of 'votes' with the most votes, preferring the larger key in the event of a tie, or
+// 'fallback' if there are no votes.
+func mostVoted(votes map[int]int, fallback int) int {
+
+	best := fallback
+	count := 0
+
+	for k, n := range votes {
+
+		if n > count || (n == count && k > best) {
+			best = k
+			count = n
+		}
+	}
+
+	return best
+}
+
+// scanPDF417Row returns the codewords of the PDF417 row crossed by 'row' or nil if 'row' does not cross a complete
+// PDF417 row, from the start pattern to the stop pattern.
+func scanPDF417Row(row []bool) []*pdf417Codeword {
+
+	for x := 0; x < len(row); x++ {
+
+		if !row[x] || (x > 0 && row[x-1]) {
+			continue
+		}
+
+		runs := runsAt(row, x, len(pdf417StartRuns))
+
+		if runs == nil || !matchRuns(runs, pdf417StartRuns, 17) {
+			continue
+		}
+
+		codewords := readPDF417Codewords(row, x+sum(runs), float64(sum(runs))/17)
+
+		if codewords != nil {
+			return codewords
+		}
+	}
+
+	return nil
+}
+
+// readPDF417Codewords reads the codewords of 'row' starting at 'x', which immediately follows a start pattern
+// whose modules are 'unit' wide, until the stop pattern is found.
+func readPDF417Codewords(row []bool, x int, unit float64) []*pdf417Codeword {
+
+	codewords := make([]*pdf417Codeword, 0)
+
+	// A symbol has at most 30 data columns and two row indicators
+
+	for len(codewords) <= 32 {
+
+		bx := nearestBarStart(row, x, int(math.Ceil(unit*2)))
+
+		// A codeword which has been blanked out, by damage or glare, is skipped so long as the row continues
+
+		if bx < 0 {
+
+			x += int(math.Round(unit * 17))
+
+			if x >= len(row) {
+				return nil
+			}
+
+			codewords = append(codewords, nil)
+			continue
+		}
+
+		runs := runsAt(row, bx, len(pdf417StopRuns))
+
+		if runs != nil && matchRuns(runs, pdf417StopRuns, 18) {
+
+			if len(codewords) < 3 {
+				return nil
+			}
+
+			return codewords
+		}
+
+		runs = runsAt(row, bx, 8)
+
+		if runs == nil {
+			return nil
+		}
+
+		// The final space of a codeword followed by a blanked out codeword runs in to it
+
+		if expected := int(math.Round(unit * 17)); sum(runs) > expected+int(unit*3) {
+			runs[7] = max(1, expected-sum(runs[:7]))
+		}
+
+		total := sum(runs)
+		cw := readPDF417Codeword(runs, total, unit)
+
+		codewords = append(codewords, cw)
+
+		if cw != nil {
+			unit = (unit*3 + float64(total)/17) / 4
+			x = bx + total
+		} else {
+			x = bx + int(math.Round(unit*17))
+		}
+	}
+
+	return nil
+}
+
+// readPDF417Codeword returns the codeword whose bars and spaces are 'runs', 'total' pixels wide, or nil if 'runs'
+// is not a codeword.
+func readPDF417Codeword(runs []int, total int, unit float64) *pdf417Codeword {
+
+	if math.Abs(float64(total)-unit*17) > unit*3 {
+		return nil
+	}
+
+	// Sample the centre of each module, then fall back to rounding the width of each bar and space
+
+	pattern := 0
+
+	for i := 0; i < 17; i++ {
+
+		pos := (float64(i) + 0.5) * float64(total) / 17
+		run := 0
+
+		for edge := float64(runs[0]); pos >= edge && run < len(runs)-1; edge += float64(runs[run]) {
+			run += 1
+		}
+
+		pattern = pattern<<1 | (1 - run%2)
+	}
+
+	cw, ok := pdf417Patterns[pattern]
+
+	if ok {
+		return cw
+	}
+
+	pattern = 0
+	modules := 0
+
+	for i, r := range runs {
+
+		n := max(1, int(math.Round(float64(r)*17/float64(total))))
+		modules += n
+
+		for j := 0; j < n; j++ {
+			pattern = pattern<<1 | (1 - i%2)
+		}
+	}
+
+	if modules != 17 {
+		return nil
+	}
+
+	return pdf417Patterns[pattern]
+}
+
+// runsAt returns the widths of the 'count' alternating bars and spaces of 'row' starting at 'x', or nil if 'row'
+// ends first.
+func runsAt(row []bool, x int, count int) []int {
+
+	runs := make([]int, 0, count)
+
+	for len(runs) < count {
+
+		if x >= len(row) {
+			return nil
+		}
+
+		start := x
+		colour := row[x]
+
+		for x < len(row) && row[x] == colour {
+			x += 1
+		}
+
+		runs = append(runs, x-start)
+	}
+
+	return runs
+}
+
+// matchRuns reports whether 'runs' matches 'expected', the widths in modules of a pattern 'modules' wide.
+func matchRuns(runs []int, expected []int, modules int) bool {
+
+	unit := float64(sum(runs)) / float64(modules)
+
+	for i, r := range runs {
+
+		if math.Abs(float64(r)/unit-float64(expected[i])) > 0.7 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nearestBarStart returns the position of the start of the bar nearest to 'x', within 'tolerance' pixels, or -1
+// if there is none.
+func nearestBarStart(row []bool, x int, tolerance int) int {
+
+	for d := 0; d <= tolerance; d++ {
+
+		for _, p := range []int{x - d, x + d} {
+
+			if p < 0 || p >= len(row) || !row[p] {
+				continue
+			}
+
+			if p == 0 || !row[p-1] {
+				return p
+			}
+		}
+	}
+
+	return -1
+}
+
+func sum(values []int) int {
+
+	total := 0
+
+	for _, v := range values {
+		total += v
+	}
+
+	return total
+}
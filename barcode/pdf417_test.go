@@ -0,0 +1,349 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+// The BCBP strings of a single leg and a four leg boarding pass.
+
+const testBCBP string = "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100"
+
+const testMultiLegBCBP string = "M4DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 106>60B        AC 2AC                                   DEF456 FRAGVAAC 0835 226F002A0026 100DEF456 GVAFRAAC 0836 226F003A0027 100DEF456 FRAYULAC 0837 226F004A0028 100"
+
+// TestPDF417RoundTrip encodes text, using every error correction level, as a PDF417 symbol rendered as an image and
+// decodes it again.
+func TestPDF417RoundTrip(t *testing.T) {
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"bcbp", testBCBP},
+		{"bcbp_multi_leg", testMultiLegBCBP},
+		{"single_character", "M"},
+		{"security_data", testBCBP + "^164GIWVC5EH7JNT684FVNJ91W2QA4DVN5J8K4F0L0GEQ3DF5TGBN8709HKT5D3DW3GBHFCVHMY7J5T6HXZ5JDJ"},
+		{"binary", "M1\x00\x01\xfe\xff"},
+	}
+
+	for _, tt := range tests {
+
+		for level := 0; level <= 8; level++ {
+
+			if level > 5 && len(tt.text) > 200 {
+				continue
+			}
+
+			im := renderPDF417(t, tt.text, level, 2)
+
+			text, err := readTestPDF417(im)
+
+			if err != nil {
+				t.Errorf("%s: level %d: failed to decode symbol, %v", tt.name, level, err)
+				continue
+			}
+
+			if text != tt.text {
+				t.Errorf("%s: level %d: expected '%s', got '%s'", tt.name, level, tt.text, text)
+			}
+		}
+	}
+}
+
+// TestPDF417Transform decodes PDF417 symbols which have been rotated and scaled, as they are in photographs and
+// scans of boarding passes.
+func TestPDF417Transform(t *testing.T) {
+
+	tests := []struct {
+		degrees float64
+		scale   float64
+	}{
+		{0, 1},
+		{90, 1},
+		{180, 1},
+		{270, 1},
+		{1, 1},
+		{-1, 1},
+		{3, 1},
+		{-3, 1},
+		{5, 1},
+		{-10, 1},
+		{15, 1},
+		{30, 1},
+		{-45, 1},
+		{92, 1},
+		{183, 1},
+		{0, 0.7},
+		{0, 0.65},
+		{0, 1.5},
+		{1, 0.7},
+		{-3, 0.8},
+		{2, 1.3},
+	}
+
+	for _, text := range []string{testBCBP, testMultiLegBCBP} {
+
+		im := renderPDF417(t, text, -1, 2)
+
+		for _, tt := range tests {
+
+			transformed := transformImage(im, tt.degrees, tt.scale)
+
+			decoded, err := readTestPDF417(transformed)
+
+			if err != nil {
+				t.Errorf("%v degrees, %vx: failed to decode %d character symbol, %v", tt.degrees, tt.scale, len(text), err)
+				continue
+			}
+
+			if decoded != text {
+				t.Errorf("%v degrees, %vx: expected '%s', got '%s'", tt.degrees, tt.scale, text, decoded)
+			}
+		}
+	}
+}
+
+// TestPDF417Damage decodes PDF417 symbols some of whose codewords have been blanked out, and so are read as
+// erasures, or overwritten.
+func TestPDF417Damage(t *testing.T) {
+
+	m, err := encodePDF417([]byte(testBCBP), 4)
+
+	if err != nil {
+		t.Fatalf("Failed to encode symbol, %v", err)
+	}
+
+	rows := m.Height / PDF417_ROW_HEIGHT
+	columns := (m.Width-1)/17 - 4
+
+	// Level 4 has 32 error correction codewords which can correct 32 erasures or 16 errors
+
+	tests := []struct {
+		name     string
+		erasures int
+		errors   int
+		ok       bool
+	}{
+		{"none", 0, 0, true},
+		{"erasures", 32, 0, true},
+		{"errors", 0, 16, true},
+		{"errors_and_erasures", 12, 10, true},
+		{"too_many_erasures", 40, 0, false},
+		{"too_many_errors", 0, 24, false},
+	}
+
+	for _, tt := range tests {
+
+		damaged := newMatrix(m.Width, m.Height)
+		copy(damaged.modules, m.modules)
+
+		// Codewords are damaged in a fixed, scattered, order
+
+		for i := 0; i < tt.erasures+tt.errors; i++ {
+
+			idx := (i * 37) % (rows * columns)
+			row := idx / columns
+			col := idx % columns
+
+			pattern := 0
+
+			if i >= tt.erasures {
+				pattern = pdf417Codewords[row%3][(i*101+7)%929]
+			}
+
+			setPattern(damaged, pattern, 17, 17*(col+2), row)
+		}
+
+		im := renderMatrix(damaged, 2, 2)
+
+		text, err := readTestPDF417(im)
+
+		if !tt.ok {
+
+			if err == nil && text == testBCBP {
+				t.Errorf("%s: expected symbol not to be corrected", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to decode symbol, %v", tt.name, err)
+			continue
+		}
+
+		if text != testBCBP {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, testBCBP, text)
+		}
+	}
+}
+
+// TestCorrectPDF417 corrects combinations of errors and erasures in the codewords of a symbol.
+func TestCorrectPDF417(t *testing.T) {
+
+	data := encodePDF417Data([]byte(testBCBP))
+	data = append([]int{len(data) + 1}, data...)
+
+	ec_count := 16
+	codewords := append(data, pdf417ErrorCorrection(data, ec_count)...)
+
+	tests := []struct {
+		errors   []int
+		erasures []int
+		ok       bool
+	}{
+		{nil, nil, true},
+		{[]int{0}, nil, true},
+		{[]int{0, 5, 10, 20, 30, 40, 50, len(codewords) - 1}, nil, true},
+		{nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, true},
+		{[]int{3, 9, 27}, []int{1, 2, 4, 8, 16, 32, 33, 34, 35, 36}, true},
+		{[]int{0, 1, 2, 3, 4, 5, 6, 7, 8}, nil, false},
+		{nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, false},
+	}
+
+	for _, tt := range tests {
+
+		name := fmt.Sprintf("%d errors and %d erasures", len(tt.errors), len(tt.erasures))
+
+		received := append([]int{}, codewords...)
+
+		for i, idx := range append(append([]int{}, tt.errors...), tt.erasures...) {
+			received[idx] = (received[idx] + 1 + i) % PDF417_MODULUS
+		}
+
+		_, err := correctPDF417(received, ec_count, tt.erasures)
+
+		if !tt.ok {
+
+			if err == nil && slices.Equal(received, codewords) {
+				t.Errorf("%s: expected codewords not to be corrected", name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to correct codewords, %v", name, err)
+			continue
+		}
+
+		if !slices.Equal(received, codewords) {
+			t.Errorf("%s: codewords were not corrected", name)
+		}
+	}
+}
+
+// renderPDF417 returns 'text' encoded as a PDF417 symbol, using the error correction level 'level', as an image
+// with 'module_size' pixel modules.
+func renderPDF417(t *testing.T, text string, level int, module_size int) *image.Gray {
+
+	t.Helper()
+
+	m, err := encodePDF417([]byte(text), level)
+
+	if err != nil {
+		t.Fatalf("Failed to encode '%s', %v", text, err)
+	}
+
+	return renderMatrix(m, module_size, PDF417_QUIET_ZONE)
+}
+
+// renderMatrix returns 'm' as a black and white image with 'module_size' pixel modules and a 'quiet_zone'
+// module margin.
+func renderMatrix(m *Matrix, module_size int, quiet_zone int) *image.Gray {
+
+	var buf bytes.Buffer
+
+	err := writePNG(m, &renderOptions{moduleSize: module_size, quietZone: quiet_zone}, &buf)
+
+	if err != nil {
+		panic(err)
+	}
+
+	im, err := readImage(&buf)
+
+	if err != nil {
+		panic(err)
+	}
+
+	bounds := im.Bounds()
+	gray := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x, y, im.At(x, y))
+		}
+	}
+
+	return gray
+}
+
+// transformImage returns 'im' rotated clockwise by 'degrees' and scaled by 'scale', using bilinear interpolation,
+// on a white background large enough to contain it.
+func transformImage(im *image.Gray, degrees float64, scale float64) *image.Gray {
+
+	theta := degrees * math.Pi / 180
+	cos := math.Cos(theta)
+	sin := math.Sin(theta)
+
+	src_width := float64(im.Bounds().Dx())
+	src_height := float64(im.Bounds().Dy())
+
+	width := int(math.Ceil((math.Abs(cos)*src_width+math.Abs(sin)*src_height)*scale)) + 20
+	height := int(math.Ceil((math.Abs(sin)*src_width+math.Abs(cos)*src_height)*scale)) + 20
+
+	out := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+
+		for x := 0; x < width; x++ {
+
+			// Map the centre of each destination pixel back to the source image
+
+			dx := (float64(x) + 0.5 - float64(width)/2) / scale
+			dy := (float64(y) + 0.5 - float64(height)/2) / scale
+
+			sx := cos*dx + sin*dy + src_width/2 - 0.5
+			sy := -sin*dx + cos*dy + src_height/2 - 0.5
+
+			x0 := int(math.Floor(sx))
+			y0 := int(math.Floor(sy))
+			fx := sx - float64(x0)
+			fy := sy - float64(y0)
+
+			l := (1-fx)*(1-fy)*grayAt(im, x0, y0) + fx*(1-fy)*grayAt(im, x0+1, y0) + (1-fx)*fy*grayAt(im, x0, y0+1) + fx*fy*grayAt(im, x0+1, y0+1)
+
+			out.SetGray(x, y, color.Gray{uint8(math.Round(l))})
+		}
+	}
+
+	return out
+}
+
+// grayAt returns the luminance of the pixel at ('x', 'y') in 'im', or white if it is outside of 'im'.
+func grayAt(im *image.Gray, x int, y int) float64 {
+
+	if !(image.Point{x, y}.In(im.Bounds())) {
+		return 255
+	}
+
+	return float64(im.GrayAt(x, y).Y)
+}
+
+// readTestPDF417 decodes the PDF417 symbol in 'im'.
+func readTestPDF417(im image.Image) (string, error) {
+
+	s, err := (&PDF417Barcode{}).decodeImage(im)
+
+	if err != nil {
+		return "", err
+	}
+
+	return s.Text, nil
+}
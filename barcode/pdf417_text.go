@@ -0,0 +1,266 @@
+package barcode
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const PDF417_TEXT_COMPACTION int = 900
+const PDF417_BYTE_COMPACTION int = 901
+const PDF417_NUMERIC_COMPACTION int = 902
+const PDF417_BYTE_SHIFT int = 913
+const PDF417_BYTE_COMPACTION_6 int = 924
+const PDF417_ECI_USER_DEFINED int = 925
+const PDF417_ECI_GENERAL_PURPOSE int = 926
+const PDF417_ECI_CHARSET int = 927
+const PDF417_MACRO_CONTROL_BLOCK int = 928
+const PDF417_MACRO_OPTIONAL_FIELD int = 923
+const PDF417_MACRO_TERMINATOR int = 922
+
+const (
+	textAlpha = iota
+	textLower
+	textMixed
+	textPunct
+)
+
+// Text compaction values 26-29 are latches and shifts (or a space), see decodeTextValues. A zero in the mixed
+// and punctuation tables marks a value which is not a character.
+
+var pdf417Mixed = []byte{
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '&', '\r', '\t', ',', ':',
+	'#', '-', '.', '$', '/', '+', '%', '*', '=', '^', 0, ' ', 0, 0, 0,
+}
+
+var pdf417Punct = []byte{
+	';', '<', '>', '@', '[', '\\', ']', '_', '`', '~', '!', '\r', '\t', ',', ':',
+	'\n', '-', '.', '$', '/', '"', '|', '*', '(', ')', '?', '{', '}', '\'', 0,
+}
+
+// decodePDF417Data decodes 'codewords', the data codewords of a PDF417 symbol excluding the symbol length
+// descriptor, in to the bytes they encode. Extended channel interpretations are ignored and decoding stops at the
+// first macro PDF417 control block.
+func decodePDF417Data(codewords []int) ([]byte, error) {
+
+	out := make([]byte, 0, len(codewords)*2)
+	idx := 0
+	mode := PDF417_TEXT_COMPACTION
+
+	for idx < len(codewords) {
+
+		// Text compaction is the default mode at the start of a symbol, every other mode begins with a latch
+
+		if codewords[idx] >= PDF417_TEXT_COMPACTION {
+			mode = codewords[idx]
+			idx += 1
+		}
+
+		var err error
+
+		switch mode {
+		case PDF417_TEXT_COMPACTION:
+			out, idx = decodeTextCompaction(codewords, idx, out)
+		case PDF417_BYTE_COMPACTION, PDF417_BYTE_COMPACTION_6:
+			out, idx = decodeByteCompaction(codewords, idx, mode, out)
+		case PDF417_NUMERIC_COMPACTION:
+			out, idx, err = decodeNumericCompaction(codewords, idx, out)
+		case PDF417_BYTE_SHIFT:
+
+			if idx < len(codewords) {
+				out = append(out, byte(codewords[idx]))
+				idx += 1
+			}
+
+			mode = PDF417_TEXT_COMPACTION
+		case PDF417_ECI_CHARSET, PDF417_ECI_USER_DEFINED:
+			idx += 1
+			mode = PDF417_TEXT_COMPACTION
+		case PDF417_ECI_GENERAL_PURPOSE:
+			idx += 2
+			mode = PDF417_TEXT_COMPACTION
+		case PDF417_MACRO_CONTROL_BLOCK, PDF417_MACRO_OPTIONAL_FIELD, PDF417_MACRO_TERMINATOR:
+			return out, nil
+		default:
+			err = fmt.Errorf("Unsupported PDF417 mode codeword %d", mode)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// decodeTextCompaction decodes the text compaction codewords in 'codewords' starting at 'idx' and appends them
+// to 'out'. It returns the updated 'out' and the offset of the first codeword which is not part of the text.
+func decodeTextCompaction(codewords []int, idx int, out []byte) ([]byte, int) {
+
+	values := make([]int, 0)
+
+	for idx < len(codewords) {
+
+		cw := codewords[idx]
+
+		if cw == PDF417_BYTE_SHIFT {
+
+			// A byte shift is encoded as a (negative) value so that it does not reset the text sub-mode
+
+			if idx+1 < len(codewords) {
+				values = append(values, -1-codewords[idx+1])
+			}
+
+			idx += 2
+			continue
+		}
+
+		if cw >= PDF417_TEXT_COMPACTION {
+			break
+		}
+
+		values = append(values, cw/30, cw%30)
+		idx += 1
+	}
+
+	return decodeTextValues(values, out), idx
+}
+
+func decodeTextValues(values []int, out []byte) []byte {
+
+	submode := textAlpha
+	shift := -1
+
+	for _, v := range values {
+
+		// A byte shift cancels a pending shift, which is how an odd number of values before it is padded
+
+		if v < 0 {
+			out = append(out, byte(-1-v))
+			shift = -1
+			continue
+		}
+
+		current := submode
+
+		if shift != -1 {
+			current = shift
+			shift = -1
+		}
+
+		switch current {
+		case textAlpha, textLower:
+
+			switch {
+			case v < 26 && current == textAlpha:
+				out = append(out, byte('A'+v))
+			case v < 26:
+				out = append(out, byte('a'+v))
+			case v == 26:
+				out = append(out, ' ')
+			case v == 27 && current == textAlpha:
+				submode = textLower
+			case v == 27:
+				// Alpha shift
+				shift = textAlpha
+			case v == 28:
+				submode = textMixed
+			default:
+				shift = textPunct
+			}
+
+		case textMixed:
+
+			switch v {
+			case 25:
+				submode = textPunct
+			case 27:
+				submode = textLower
+			case 28:
+				submode = textAlpha
+			case 29:
+				shift = textPunct
+			default:
+				out = append(out, pdf417Mixed[v])
+			}
+
+		case textPunct:
+
+			if v == 29 {
+				submode = textAlpha
+			} else {
+				out = append(out, pdf417Punct[v])
+			}
+		}
+	}
+
+	return out
+}
+
+// decodeByteCompaction decodes the byte compaction codewords in 'codewords' starting at 'idx' and appends them
+// to 'out'. Groups of five codewords encode six bytes. In 'mode' PDF417_BYTE_COMPACTION the final group, which is
+// not followed by further byte compaction codewords, encodes one byte per codeword.
+func decodeByteCompaction(codewords []int, idx int, mode int, out []byte) ([]byte, int) {
+
+	end := idx
+
+	for end < len(codewords) && codewords[end] < PDF417_TEXT_COMPACTION {
+		end += 1
+	}
+
+	for idx < end {
+
+		if end-idx >= 5 && (mode == PDF417_BYTE_COMPACTION_6 || end-idx > 5) {
+
+			v := 0
+
+			for _, cw := range codewords[idx : idx+5] {
+				v = v*900 + cw
+			}
+
+			for shift := 40; shift >= 0; shift -= 8 {
+				out = append(out, byte(v>>shift))
+			}
+
+			idx += 5
+			continue
+		}
+
+		out = append(out, byte(codewords[idx]))
+		idx += 1
+	}
+
+	return out, idx
+}
+
+// decodeNumericCompaction decodes the numeric compaction codewords in 'codewords' starting at 'idx' and appends
+// them to 'out'. Each group of up to 15 codewords is a base 900 number whose decimal representation has a leading 1.
+func decodeNumericCompaction(codewords []int, idx int, out []byte) ([]byte, int, error) {
+
+	for idx < len(codewords) && codewords[idx] < PDF417_TEXT_COMPACTION {
+
+		end := idx
+
+		for end < len(codewords) && end-idx < 15 && codewords[end] < PDF417_TEXT_COMPACTION {
+			end += 1
+		}
+
+		n := big.NewInt(0)
+		base := big.NewInt(900)
+
+		for _, cw := range codewords[idx:end] {
+			n.Mul(n, base)
+			n.Add(n, big.NewInt(int64(cw)))
+		}
+
+		digits := n.String()
+
+		if len(digits) < 2 || digits[0] != '1' {
+			return nil, 0, fmt.Errorf("Invalid numeric compaction group at codeword %d", idx)
+		}
+
+		out = append(out, digits[1:]...)
+		idx = end
+	}
+
+	return out, idx, nil
+}
@@ -0,0 +1,100 @@
+package barcode
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPDF417Compaction encodes text which exercises every compaction mode, and text compaction sub-mode, and
+// decodes it again.
+func TestPDF417Compaction(t *testing.T) {
+
+	tests := []struct {
+		name string
+		text string
+		// A mode latch which must be present in the codewords, or 0.
+		latch int
+	}{
+		{"alpha", "DESMARAIS LUC", 0},
+		{"lower", "desmarais luc", 0},
+		{"alpha_shift", "dESMARAIs Luc", 0},
+		{"mixed", "YUL 0834&FRA", 0},
+		{"punctuation", "M1DESMARAIS/LUC>;<[]", 0},
+		{"punctuation_latch", "0834!?\"'(){}@", 0},
+		{"control_characters", "M1\tLUC\r\n", 0},
+		{"numeric", "1234567890123", PDF417_NUMERIC_COMPACTION},
+		{"numeric_leading_zeros", "0000000000000", PDF417_NUMERIC_COMPACTION},
+		{"numeric_groups", strings.Repeat("0123456789", 10), PDF417_NUMERIC_COMPACTION},
+		{"numeric_then_text", "01412345678901 AC", PDF417_NUMERIC_COMPACTION},
+		{"short_numeric", "014123456789", 0},
+		{"byte", "\x00\x01\x02", PDF417_BYTE_COMPACTION},
+		{"byte_groups", "\x80\x81\x82\x83\x84\x85\x86\x87\x88\x89\x8a\x8b", PDF417_BYTE_COMPACTION_6},
+		{"byte_partial_group", "\xff\xfe\xfd\xfc\xfb\xfa\xf9", PDF417_BYTE_COMPACTION},
+		{"byte_then_text", "\xe9\xe8 DESMARAIS", PDF417_TEXT_COMPACTION},
+		{"bcbp", testBCBP, 0},
+	}
+
+	for _, tt := range tests {
+
+		codewords := encodePDF417Data([]byte(tt.text))
+
+		if tt.latch != 0 && !slices.Contains(codewords, tt.latch) {
+			t.Errorf("%s: expected codewords %v to contain the mode latch %d", tt.name, codewords, tt.latch)
+		}
+
+		for _, cw := range codewords {
+
+			if cw < 0 || cw >= PDF417_MODULUS {
+				t.Errorf("%s: invalid codeword %d", tt.name, cw)
+			}
+		}
+
+		data, err := decodePDF417Data(codewords)
+
+		if err != nil {
+			t.Errorf("%s: failed to decode codewords, %v", tt.name, err)
+			continue
+		}
+
+		if string(data) != tt.text {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.text, string(data))
+		}
+	}
+}
+
+// TestDecodePDF417Data decodes codewords which this package does not write, but other encoders do.
+func TestDecodePDF417Data(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		codewords []int
+		expected  string
+	}{
+		// "AB" then a byte shift for 0xe9 then "C" and the padding of an odd number of values
+		{"byte_shift", []int{0*30 + 1, PDF417_BYTE_SHIFT, 0xe9, 2*30 + 29}, "AB\xe9C"},
+		{"eci", []int{PDF417_ECI_CHARSET, 3, 0*30 + 1}, "AB"},
+		{"macro_control_block", []int{0*30 + 1, PDF417_MACRO_CONTROL_BLOCK, 1, 2, 3}, "AB"},
+		{"padding", []int{0*30 + 1, PDF417_TEXT_COMPACTION, PDF417_TEXT_COMPACTION}, "AB"},
+	}
+
+	for _, tt := range tests {
+
+		data, err := decodePDF417Data(tt.codewords)
+
+		if err != nil {
+			t.Errorf("%s: failed to decode codewords, %v", tt.name, err)
+			continue
+		}
+
+		if string(data) != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, string(data))
+		}
+	}
+
+	_, err := decodePDF417Data([]int{PDF417_NUMERIC_COMPACTION, 0})
+
+	if err == nil {
+		t.Errorf("Expected invalid numeric compaction to fail")
+	}
+}
@@ -0,0 +1,465 @@
+package barcode
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// PDF417_ROW_HEIGHT is the height, in modules, of each row of a rendered PDF417 symbol.
+const PDF417_ROW_HEIGHT int = 3
+
+// PDF417_ASPECT_RATIO is the preferred ratio of the width of a rendered PDF417 symbol to its height.
+const PDF417_ASPECT_RATIO float64 = 3.0
+
+// PDF417_MIN_NUMERIC is the minimum number of consecutive digits which are encoded using numeric compaction.
+const PDF417_MIN_NUMERIC int = 13
+
+// PDF417_MIN_TEXT is the minimum number of consecutive text characters which are encoded using text compaction
+// once byte compaction has been used.
+const PDF417_MIN_TEXT int = 5
+
+// pdf417ErrorCorrectionLevel returns the minimum error correction level recommended for a symbol with 'count' data
+// codewords.
+func pdf417ErrorCorrectionLevel(count int) int {
+
+	switch {
+	case count <= 40:
+		return 2
+	case count <= 160:
+		return 3
+	case count <= 320:
+		return 4
+	case count <= 863:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// encodePDF417 encodes 'data' as the modules of a PDF417 symbol with the error correction level 'level' or, if
+// 'level' is -1, the level recommended for the amount of data.
+func encodePDF417(data []byte, level int) (*Matrix, error) {
+
+	words := encodePDF417Data(data)
+
+	if level == -1 {
+		level = pdf417ErrorCorrectionLevel(len(words) + 1)
+	}
+
+	ec_count := 1 << (level + 1)
+	count := len(words) + 1 + ec_count
+
+	columns, rows, err := pdf417Dimensions(count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The symbol length descriptor counts itself, the data codewords and the padding
+
+	codewords := make([]int, 0, rows*columns)
+	codewords = append(codewords, rows*columns-ec_count)
+	codewords = append(codewords, words...)
+
+	for len(codewords) < rows*columns-ec_count {
+		codewords = append(codewords, PDF417_TEXT_COMPACTION)
+	}
+
+	codewords = append(codewords, pdf417ErrorCorrection(codewords, ec_count)...)
+
+	m := newMatrix(17*(columns+4)+1, rows*PDF417_ROW_HEIGHT)
+
+	for row := 0; row < rows; row++ {
+
+		cluster := row % 3
+		group := 30 * (row / 3)
+
+		var left int
+		var right int
+
+		switch cluster {
+		case 0:
+			left = group + (rows-1)/3
+			right = group + columns - 1
+		case 1:
+			left = group + level*3 + (rows-1)%3
+			right = group + (rows-1)/3
+		default:
+			left = group + columns - 1
+			right = group + level*3 + (rows-1)%3
+		}
+
+		patterns := make([]int, 0, columns+4)
+		patterns = append(patterns, PDF417_START_PATTERN, pdf417Codewords[cluster][left])
+
+		for _, cw := range codewords[row*columns : (row+1)*columns] {
+			patterns = append(patterns, pdf417Codewords[cluster][cw])
+		}
+
+		patterns = append(patterns, pdf417Codewords[cluster][right])
+
+		x := 0
+
+		for _, p := range patterns {
+			x = setPattern(m, p, 17, x, row)
+		}
+
+		setPattern(m, PDF417_STOP_PATTERN, 18, x, row)
+	}
+
+	return m, nil
+}
+
+// setPattern sets the 'width' modules of the PDF417 row 'row' of 'm', starting at column 'x', to the bits of
+// 'pattern', most significant bit first. It returns the column following the pattern.
+func setPattern(m *Matrix, pattern int, width int, x int, row int) int {
+
+	for i := 0; i < width; i++ {
+
+		dark := pattern&(1<<(width-1-i)) != 0
+
+		for dy := 0; dy < PDF417_ROW_HEIGHT; dy++ {
+			m.set(x+i, row*PDF417_ROW_HEIGHT+dy, dark)
+		}
+	}
+
+	return x + width
+}
+
+// pdf417Dimensions returns the number of columns and rows of a symbol, of between 3 and 90 rows and 1 and 30
+// columns, for 'count' codewords whose rendered aspect ratio is closest to PDF417_ASPECT_RATIO.
+func pdf417Dimensions(count int) (int, int, error) {
+
+	best_columns := 0
+	best_rows := 0
+	best := math.Inf(1)
+
+	for columns := 1; columns <= 30; columns++ {
+
+		rows := max(3, (count+columns-1)/columns)
+
+		if rows > 90 || rows*columns > 928 {
+			continue
+		}
+
+		ratio := float64(17*(columns+4)+1) / float64(rows*PDF417_ROW_HEIGHT)
+		diff := math.Abs(ratio - PDF417_ASPECT_RATIO)
+
+		if diff < best {
+			best = diff
+			best_columns = columns
+			best_rows = rows
+		}
+	}
+
+	if best_columns == 0 {
+		return 0, 0, fmt.Errorf("Too much data for a PDF417 symbol, %d codewords", count)
+	}
+
+	return best_columns, best_rows, nil
+}
+
+// pdf417ErrorCorrection returns the 'ec_count' error correction codewords for 'codewords'. The generator
+// polynomial has the roots 3^1 ... 3^ec_count.
+func pdf417ErrorCorrection(codewords []int, ec_count int) []int {
+
+	generator := gfPoly{1}
+
+	for i := 1; i <= ec_count; i++ {
+		generator = generator.multiply(gfPoly{1, gfSubtract(0, gf929Exp[i])})
+	}
+
+	remainder := make([]int, len(codewords)+ec_count)
+	copy(remainder, codewords)
+
+	for i := range codewords {
+
+		coefficient := remainder[i]
+
+		if coefficient == 0 {
+			continue
+		}
+
+		for j := 1; j <= ec_count; j++ {
+			remainder[i+j] = gfSubtract(remainder[i+j], gfMultiply(coefficient, generator[j]))
+		}
+	}
+
+	ec := make([]int, ec_count)
+
+	for i, r := range remainder[len(codewords):] {
+		ec[i] = gfSubtract(0, r)
+	}
+
+	return ec
+}
+
+// encodePDF417Data encodes 'data' as PDF417 data codewords, excluding the symbol length descriptor. Runs of
+// digits are encoded using numeric compaction, text using text compaction and anything else using byte compaction.
+func encodePDF417Data(data []byte) []int {
+
+	codewords := make([]int, 0, len(data))
+	mode := PDF417_TEXT_COMPACTION
+	idx := 0
+
+	for idx < len(data) {
+
+		digits := countPDF417Digits(data[idx:])
+
+		if digits >= PDF417_MIN_NUMERIC {
+			codewords = append(codewords, PDF417_NUMERIC_COMPACTION)
+			codewords = append(codewords, encodeNumericCompaction(data[idx:idx+digits])...)
+			mode = PDF417_NUMERIC_COMPACTION
+			idx += digits
+			continue
+		}
+
+		text := countPDF417Text(data[idx:])
+
+		if text > 0 && (text >= PDF417_MIN_TEXT || mode == PDF417_TEXT_COMPACTION || idx+text == len(data)) {
+
+			// Text compaction is the default mode at the start of a symbol
+
+			if mode != PDF417_TEXT_COMPACTION {
+				codewords = append(codewords, PDF417_TEXT_COMPACTION)
+				mode = PDF417_TEXT_COMPACTION
+			}
+
+			codewords = append(codewords, encodeTextCompaction(data[idx:idx+text])...)
+			idx += text
+			continue
+		}
+
+		end := idx + 1
+
+		for end < len(data) && countPDF417Digits(data[end:]) < PDF417_MIN_NUMERIC && countPDF417Text(data[end:]) < PDF417_MIN_TEXT {
+			end += 1
+		}
+
+		codewords = append(codewords, encodeByteCompaction(data[idx:end])...)
+		mode = PDF417_BYTE_COMPACTION
+		idx = end
+	}
+
+	return codewords
+}
+
+// countPDF417Digits returns the number of consecutive digits at the start of 'data'.
+func countPDF417Digits(data []byte) int {
+
+	count := 0
+
+	for count < len(data) && data[count] >= '0' && data[count] <= '9' {
+		count += 1
+	}
+
+	return count
+}
+
+// countPDF417Text returns the number of consecutive characters at the start of 'data' which can be encoded using
+// text compaction, stopping at the first run of digits which should be encoded using numeric compaction.
+func countPDF417Text(data []byte) int {
+
+	count := 0
+
+	for count < len(data) {
+
+		c := data[count]
+
+		if c != '\t' && c != '\n' && c != '\r' && (c < ' ' || c > '~') {
+			break
+		}
+
+		if countPDF417Digits(data[count:]) >= PDF417_MIN_NUMERIC {
+			break
+		}
+
+		count += 1
+	}
+
+	return count
+}
+
+// encodeTextCompaction encodes 'data', which must only contain characters which can be encoded using text
+// compaction, starting in the alpha sub-mode.
+func encodeTextCompaction(data []byte) []int {
+
+	values := make([]int, 0, len(data)*2)
+	submode := textAlpha
+
+	for idx := 0; idx < len(data); {
+
+		c := data[idx]
+		upper := c == ' ' || (c >= 'A' && c <= 'Z')
+		lower := c == ' ' || (c >= 'a' && c <= 'z')
+		mixed := indexOf(pdf417Mixed, c)
+		punct := indexOf(pdf417Punct, c)
+
+		switch submode {
+		case textAlpha:
+
+			switch {
+			case c == ' ':
+				values = append(values, 26)
+			case upper:
+				values = append(values, int(c-'A'))
+			case lower:
+				values = append(values, 27)
+				submode = textLower
+				continue
+			case mixed != -1:
+				values = append(values, 28)
+				submode = textMixed
+				continue
+			default:
+				// Punctuation shift
+				values = append(values, 29, punct)
+			}
+
+		case textLower:
+
+			switch {
+			case c == ' ':
+				values = append(values, 26)
+			case lower:
+				values = append(values, int(c-'a'))
+			case upper:
+				// Alpha shift
+				values = append(values, 27, int(c-'A'))
+			case mixed != -1:
+				values = append(values, 28)
+				submode = textMixed
+				continue
+			default:
+				values = append(values, 29, punct)
+			}
+
+		case textMixed:
+
+			switch {
+			case mixed != -1:
+				values = append(values, mixed)
+			case upper:
+				values = append(values, 28)
+				submode = textAlpha
+				continue
+			case lower:
+				values = append(values, 27)
+				submode = textLower
+				continue
+			case idx+1 < len(data) && indexOf(pdf417Punct, data[idx+1]) != -1:
+				values = append(values, 25)
+				submode = textPunct
+				continue
+			default:
+				values = append(values, 29, punct)
+			}
+
+		default:
+
+			if punct == -1 {
+				values = append(values, 29)
+				submode = textAlpha
+				continue
+			}
+
+			values = append(values, punct)
+		}
+
+		idx += 1
+	}
+
+	// An odd number of values is padded with a punctuation shift
+
+	if len(values)%2 == 1 {
+		values = append(values, 29)
+	}
+
+	codewords := make([]int, len(values)/2)
+
+	for i := range codewords {
+		codewords[i] = values[i*2]*30 + values[i*2+1]
+	}
+
+	return codewords
+}
+
+// indexOf returns the index of 'c' in the text compaction table 'table', or -1 if it is not present.
+func indexOf(table []byte, c byte) int {
+
+	for i, t := range table {
+
+		if t != 0 && t == c {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// encodeByteCompaction encodes 'data', including the mode latch, using byte compaction. Groups of six bytes are
+// encoded as five codewords and any remaining bytes as one codeword each.
+func encodeByteCompaction(data []byte) []int {
+
+	mode := PDF417_BYTE_COMPACTION
+
+	if len(data)%6 == 0 {
+		mode = PDF417_BYTE_COMPACTION_6
+	}
+
+	codewords := []int{mode}
+	idx := 0
+
+	for ; idx+6 <= len(data); idx += 6 {
+
+		v := 0
+
+		for _, b := range data[idx : idx+6] {
+			v = v<<8 | int(b)
+		}
+
+		group := make([]int, 5)
+
+		for i := 4; i >= 0; i-- {
+			group[i] = v % 900
+			v = v / 900
+		}
+
+		codewords = append(codewords, group...)
+	}
+
+	for _, b := range data[idx:] {
+		codewords = append(codewords, int(b))
+	}
+
+	return codewords
+}
+
+// encodeNumericCompaction encodes 'digits' using numeric compaction. Each group of up to 44 digits is prefixed
+// with a 1 and encoded as a base 900 number.
+func encodeNumericCompaction(digits []byte) []int {
+
+	codewords := make([]int, 0)
+	base := big.NewInt(900)
+
+	for idx := 0; idx < len(digits); idx += 44 {
+
+		end := min(idx+44, len(digits))
+
+		n, _ := new(big.Int).SetString("1"+string(digits[idx:end]), 10)
+		group := make([]int, 0)
+
+		for n.Sign() > 0 {
+
+			m := new(big.Int)
+			n.DivMod(n, base, m)
+
+			group = append([]int{int(m.Int64())}, group...)
+		}
+
+		codewords = append(codewords, group...)
+	}
+
+	return codewords
+}
@@ -0,0 +1,70 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// SYMBOLOGY_QR is the symbology of QR codes, used by some carriers and rail/air codeshares.
+const SYMBOLOGY_QR string = "qr"
+
+// QRBarcode implements the `bcbp.Barcode` interface for QR codes.
+type QRBarcode struct{}
+
+func init() {
+	ctx := context.Background()
+	bcbp.RegisterBarcode(ctx, "qr", NewQRBarcode)
+}
+
+// NewQRBarcode returns a new `QRBarcode` instance configured by 'uri' which is expected to take the form of:
+//
+//	qr://
+func NewQRBarcode(ctx context.Context, uri string) (bcbp.Barcode, error) {
+	bc := &QRBarcode{}
+	return bc, nil
+}
+
+// Encode is not implemented yet and returns an error.
+func (bc *QRBarcode) Encode(b *bcbp.BCBP, wr io.Writer) error {
+	return fmt.Errorf("Encoding QR codes is not supported")
+}
+
+// Decode decodes the QR code in the image data contained in 'r' and parses its text as a BCBP string, in
+// lenient mode.
+func (bc *QRBarcode) Decode(r io.Reader) (*bcbp.BCBP, error) {
+
+	s, err := bc.DecodeSymbol(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSymbol(s)
+}
+
+// DecodeSymbol decodes the QR code in the PNG, JPEG or GIF image data contained in 'r'. The symbol may be at any
+// orientation.
+func (bc *QRBarcode) DecodeSymbol(r io.Reader) (*Symbol, error) {
+	return decodeSymbol(bc, r)
+}
+
+func (bc *QRBarcode) decodeImage(im image.Image) (*Symbol, error) {
+
+	text, err := readZXing(qrcode.NewQRCodeReader(), im)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read QR code, %w", err)
+	}
+
+	s := &Symbol{
+		Symbology: SYMBOLOGY_QR,
+		Text:      text,
+	}
+
+	return s, nil
+}
@@ -0,0 +1,195 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/sfomuseum/go-bcbp"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// Matrix is the grid of square modules of a barcode symbol, excluding any quiet zone.
+type Matrix struct {
+	// The width of the symbol in modules.
+	Width int
+	// The height of the symbol in modules.
+	Height int
+	// The modules of the symbol, row by row, where true is a dark (bar) module.
+	modules []bool
+}
+
+// SymbolEncoder is implemented by the `bcbp.Barcode` instances in this package which can encode barcodes. It
+// encodes text, rather than a `bcbp.BCBP` instance, allowing a BCBP string to be encoded exactly as it is.
+type SymbolEncoder interface {
+	// EncodeSymbol encodes text as a barcode symbol written to an `io.Writer` instance as a PNG image.
+	EncodeSymbol(string, io.Writer) error
+	// EncodeSVG encodes text as a barcode symbol written to an `io.Writer` instance as an SVG document.
+	EncodeSVG(string, *SVGOptions, io.Writer) error
+	// EncodeMatrix encodes text as the modules of a barcode symbol.
+	EncodeMatrix(string) (*Matrix, error)
+}
+
+// renderOptions are the options, derived from the URI of a `bcbp.Barcode` instance, for rendering barcodes.
+type renderOptions struct {
+	// The width and height, in pixels, of each module.
+	moduleSize int
+	// The width, in modules, of the blank margin surrounding the symbol.
+	quietZone int
+}
+
+func newMatrix(width int, height int) *Matrix {
+
+	m := &Matrix{
+		Width:   width,
+		Height:  height,
+		modules: make([]bool, width*height),
+	}
+
+	return m
+}
+
+// Get reports whether the module at column 'x' and row 'y' of 'm' is dark.
+func (m *Matrix) Get(x int, y int) bool {
+	return m.modules[y*m.Width+x]
+}
+
+func (m *Matrix) set(x int, y int, dark bool) {
+	m.modules[y*m.Width+x] = dark
+}
+
+// EncodeSymbol encodes 'text' as a barcode symbol, written to 'wr' as a PNG image, using the `bcbp.Barcode`
+// instance configured by 'uri'.
+func EncodeSymbol(ctx context.Context, uri string, text string, wr io.Writer) error {
+
+	e, err := newSymbolEncoder(ctx, uri)
+
+	if err != nil {
+		return err
+	}
+
+	return e.EncodeSymbol(text, wr)
+}
+
+// newSymbolEncoder returns the `bcbp.Barcode` instance configured by 'uri' as a `SymbolEncoder`.
+func newSymbolEncoder(ctx context.Context, uri string) (SymbolEncoder, error) {
+
+	bc, err := bcbp.NewBarcode(ctx, uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create barcode for '%s', %w", uri, err)
+	}
+
+	e, ok := bc.(SymbolEncoder)
+
+	if !ok {
+		return nil, fmt.Errorf("Barcode for '%s' does not support encoding symbols", uri)
+	}
+
+	return e, nil
+}
+
+// newRenderOptions returns the "module_size" and "quiet_zone" parameters of 'q', defaulting to 'module_size' and
+// 'quiet_zone' respectively.
+func newRenderOptions(q url.Values, module_size int, quiet_zone int) (*renderOptions, error) {
+
+	module_size, err := intParameter(q, "module_size", module_size, 1, 100)
+
+	if err != nil {
+		return nil, err
+	}
+
+	quiet_zone, err = intParameter(q, "quiet_zone", quiet_zone, 0, 100)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &renderOptions{
+		moduleSize: module_size,
+		quietZone:  quiet_zone,
+	}
+
+	return opts, nil
+}
+
+// intParameter returns the value of the query parameter 'name' in 'q', which must be between 'minimum' and
+// 'maximum' inclusive, or 'fallback' if it is absent.
+func intParameter(q url.Values, name string, fallback int, minimum int, maximum int) (int, error) {
+
+	if !q.Has(name) {
+		return fallback, nil
+	}
+
+	v, err := strconv.Atoi(q.Get(name))
+
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s parameter, %w", name, err)
+	}
+
+	if v < minimum || v > maximum {
+		return 0, fmt.Errorf("Invalid %s parameter %d, expected a value between %d and %d", name, v, minimum, maximum)
+	}
+
+	return v, nil
+}
+
+// writePNG writes 'm' to 'wr' as a black and white PNG image using 'opts'.
+func writePNG(m *Matrix, opts *renderOptions, wr io.Writer) error {
+
+	palette := color.Palette{color.White, color.Black}
+
+	width := (m.Width + opts.quietZone*2) * opts.moduleSize
+	height := (m.Height + opts.quietZone*2) * opts.moduleSize
+
+	im := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+
+	for y := 0; y < m.Height; y++ {
+
+		for x := 0; x < m.Width; x++ {
+
+			if !m.Get(x, y) {
+				continue
+			}
+
+			x0 := (x + opts.quietZone) * opts.moduleSize
+			y0 := (y + opts.quietZone) * opts.moduleSize
+
+			for dy := 0; dy < opts.moduleSize; dy++ {
+
+				for dx := 0; dx < opts.moduleSize; dx++ {
+					im.SetColorIndex(x0+dx, y0+dy, 1)
+				}
+			}
+		}
+	}
+
+	err := png.Encode(wr, im)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode PNG, %w", err)
+	}
+
+	return nil
+}
+
+// marshalSymbol encodes 'b' as a BCBP string with its legs concatenated as described by Resolution 792.
+func marshalSymbol(b *bcbp.BCBP) (string, error) {
+
+	bp := &boardingpass.BCBP{
+		Legs: make([]*boardingpass.Leg, len(b.Legs)),
+	}
+
+	for idx, l := range b.Legs {
+		bp.Legs[idx] = &boardingpass.Leg{
+			Leg: l,
+		}
+	}
+
+	return boardingpass.Marshal(bp)
+}
@@ -0,0 +1,152 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/url"
+	"testing"
+)
+
+// TestEncodeSymbol renders symbols as PNG images and ensures that their dimensions, and the position of their
+// modules, follow from the module size and quiet zone.
+func TestEncodeSymbol(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		uri string
+		// Whether an error is expected.
+		err         bool
+		module_size int
+		quiet_zone  int
+	}{
+		{"pdf417://", false, PDF417_MODULE_SIZE, PDF417_QUIET_ZONE},
+		{"pdf417://?module_size=3&quiet_zone=0", false, 3, 0},
+		{"pdf417://?error_correction=5&module_size=1", false, 1, PDF417_QUIET_ZONE},
+		{"aztec://", false, AZTEC_MODULE_SIZE, AZTEC_QUIET_ZONE},
+		{"aztec://?module_size=1&quiet_zone=10", false, 1, 10},
+		{"pdf417://?module_size=101", true, 0, 0},
+		{"aztec://?quiet_zone=x", true, 0, 0},
+		{"qr://", true, 0, 0},
+		{"datamatrix://", true, 0, 0},
+		{"auto://", true, 0, 0},
+		{"code128://", true, 0, 0},
+	}
+
+	for _, tt := range tests {
+
+		var buf bytes.Buffer
+
+		err := EncodeSymbol(ctx, tt.uri, testBCBP, &buf)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("'%s': expected an error", tt.uri)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("'%s': failed to encode symbol, %v", tt.uri, err)
+			continue
+		}
+
+		im, err := png.Decode(&buf)
+
+		if err != nil {
+			t.Errorf("'%s': failed to decode PNG, %v", tt.uri, err)
+			continue
+		}
+
+		e, err := newSymbolEncoder(ctx, tt.uri)
+
+		if err != nil {
+			t.Errorf("'%s': failed to create encoder, %v", tt.uri, err)
+			continue
+		}
+
+		m, err := e.EncodeMatrix(testBCBP)
+
+		if err != nil {
+			t.Errorf("'%s': failed to encode matrix, %v", tt.uri, err)
+			continue
+		}
+
+		width := (m.Width + tt.quiet_zone*2) * tt.module_size
+		height := (m.Height + tt.quiet_zone*2) * tt.module_size
+
+		bounds := im.Bounds()
+
+		if bounds.Dx() != width || bounds.Dy() != height {
+			t.Errorf("'%s': expected %dx%d image, got %dx%d", tt.uri, width, height, bounds.Dx(), bounds.Dy())
+			continue
+		}
+
+		for y := 0; y < m.Height; y++ {
+
+			for x := 0; x < m.Width; x++ {
+
+				px := (x+tt.quiet_zone)*tt.module_size + tt.module_size - 1
+				py := (y+tt.quiet_zone)*tt.module_size + tt.module_size - 1
+
+				r, _, _, _ := im.At(px, py).RGBA()
+
+				if (r == 0) != m.Get(x, y) {
+					t.Errorf("'%s': expected module %d,%d to be dark %t", tt.uri, x, y, m.Get(x, y))
+				}
+			}
+		}
+	}
+}
+
+// TestIntParameter reads integer query parameters, with defaults and range checks.
+func TestIntParameter(t *testing.T) {
+
+	tests := []struct {
+		query string
+		// Whether an error is expected.
+		err   bool
+		value int
+	}{
+		{"", false, 7},
+		{"n=1", false, 1},
+		{"n=10", false, 10},
+		{"n=0", true, 0},
+		{"n=11", true, 0},
+		{"n=", true, 0},
+		{"n=2.5", true, 0},
+	}
+
+	for _, tt := range tests {
+
+		q, err := url.ParseQuery(tt.query)
+
+		if err != nil {
+			t.Errorf("'%s': failed to parse query, %v", tt.query, err)
+			continue
+		}
+
+		v, err := intParameter(q, "n", 7, 1, 10)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("'%s': expected an error", tt.query)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("'%s': failed to read parameter, %v", tt.query, err)
+			continue
+		}
+
+		if v != tt.value {
+			t.Errorf("'%s': expected %d, got %d", tt.query, tt.value, v)
+		}
+	}
+}
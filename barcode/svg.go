@@ -0,0 +1,218 @@
+package barcode
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SVG_FOREGROUND is the default colour of the dark modules, and text, of SVG barcodes.
+const SVG_FOREGROUND string = "#000000"
+
+// SVG_BACKGROUND is the default colour of the light modules, and margins, of SVG barcodes.
+const SVG_BACKGROUND string = "#ffffff"
+
+// SVG_FONT_FAMILY is the default font family of the human-readable text of SVG barcodes.
+const SVG_FONT_FAMILY string = "monospace"
+
+// SVG_MAX_FONT_SIZE is the maximum size, in modules, of the human-readable text of SVG barcodes when no font size
+// is specified.
+const SVG_MAX_FONT_SIZE float64 = 8.0
+
+// svgLength matches the SVG lengths, a number with an optional unit, accepted for the dimensions of SVG barcodes.
+var svgLength = regexp.MustCompile(`^\d+(\.\d+)?(px|mm|cm|in|pt|pc|%)?$`)
+
+// SVGOptions are the options for rendering barcodes as SVG documents. Coordinates in the document are measured in
+// modules so the symbol can be scaled to any size without losing detail.
+type SVGOptions struct {
+	// The colour of the dark modules and the human-readable text. Default is SVG_FOREGROUND.
+	Foreground string
+	// The colour of the light modules and the margins, or "none" for a transparent background. Default is
+	// SVG_BACKGROUND.
+	Background string
+	// The width of the document, for example "80mm" or "400". If only one of Width and Height is specified the
+	// other is derived from the aspect ratio of the document. If neither is specified the document is sized using
+	// the module size of the `bcbp.Barcode` instance.
+	Width string
+	// The height of the document, for example "30mm" or "150".
+	Height string
+	// Optional human-readable text drawn under the symbol.
+	Text string
+	// The font family of the human-readable text. Default is SVG_FONT_FAMILY.
+	FontFamily string
+	// The size, in modules, of the human-readable text. Default is the largest size, up to SVG_MAX_FONT_SIZE,
+	// at which the text fits the width of the document.
+	FontSize float64
+}
+
+// EncodeSVG encodes 'text' as a barcode symbol, written to 'wr' as an SVG document using 'opts', using the
+// `bcbp.Barcode` instance configured by 'uri'.
+func EncodeSVG(ctx context.Context, uri string, text string, opts *SVGOptions, wr io.Writer) error {
+
+	e, err := newSymbolEncoder(ctx, uri)
+
+	if err != nil {
+		return err
+	}
+
+	return e.EncodeSVG(text, opts, wr)
+}
+
+// writeSVG writes 'm' to 'wr' as an SVG document using 'render' and 'opts'. Horizontal runs of dark modules are
+// drawn as a single path.
+func writeSVG(m *Matrix, render *renderOptions, opts *SVGOptions, wr io.Writer) error {
+
+	if opts == nil {
+		opts = &SVGOptions{}
+	}
+
+	for _, v := range []string{opts.Width, opts.Height} {
+
+		if v != "" && !svgLength.MatchString(v) {
+			return fmt.Errorf("Invalid SVG length '%s'", v)
+		}
+	}
+
+	if opts.FontSize < 0 {
+		return fmt.Errorf("Invalid font size %v", opts.FontSize)
+	}
+
+	foreground := svgOption(opts.Foreground, SVG_FOREGROUND)
+	background := svgOption(opts.Background, SVG_BACKGROUND)
+	font_family := svgOption(opts.FontFamily, SVG_FONT_FAMILY)
+
+	width := float64(m.Width + render.quietZone*2)
+	height := float64(m.Height + render.quietZone*2)
+
+	// Monospace glyphs are roughly 0.6 times as wide as the font size
+
+	text := svgText(opts.Text)
+	length := float64(utf8.RuneCountInString(text))
+
+	font_size := opts.FontSize
+	text_width := 0.0
+	text_height := 0.0
+
+	if text != "" {
+
+		if font_size == 0 {
+			font_size = min(SVG_MAX_FONT_SIZE, width/(0.6*length))
+		}
+
+		text_width = 0.6 * font_size * length
+		text_height = font_size * 1.5
+	}
+
+	total_height := height + text_height
+
+	svg_width := opts.Width
+	svg_height := opts.Height
+
+	if svg_width == "" && svg_height == "" {
+		svg_width = formatSVGNumber(width * float64(render.moduleSize))
+		svg_height = formatSVGNumber(total_height * float64(render.moduleSize))
+	}
+
+	buf := bufio.NewWriter(wr)
+
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %s %s"`, formatSVGNumber(width), formatSVGNumber(total_height))
+
+	if svg_width != "" {
+		fmt.Fprintf(buf, ` width="%s"`, svg_width)
+	}
+
+	if svg_height != "" {
+		fmt.Fprintf(buf, ` height="%s"`, svg_height)
+	}
+
+	fmt.Fprintf(buf, ` shape-rendering="crispEdges">`)
+
+	if background != "none" {
+		fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="%s"/>`, html.EscapeString(background))
+	}
+
+	fmt.Fprintf(buf, `<path fill="%s" d="`, html.EscapeString(foreground))
+
+	for y := 0; y < m.Height; y++ {
+
+		for x := 0; x < m.Width; {
+
+			if !m.Get(x, y) {
+				x += 1
+				continue
+			}
+
+			start := x
+
+			for x < m.Width && m.Get(x, y) {
+				x += 1
+			}
+
+			fmt.Fprintf(buf, "M%d %dh%dv1h-%dz", start+render.quietZone, y+render.quietZone, x-start, x-start)
+		}
+	}
+
+	fmt.Fprintf(buf, `"/>`)
+
+	if text != "" {
+
+		fmt.Fprintf(buf, `<text x="%s" y="%s" font-family="%s" font-size="%s" fill="%s" text-anchor="middle" xml:space="preserve"`, formatSVGNumber(width/2), formatSVGNumber(height+font_size), html.EscapeString(font_family), formatSVGNumber(font_size), html.EscapeString(foreground))
+
+		// Text which (probably) does not fit the width of the document is squeezed to fit
+
+		if text_width > width {
+			fmt.Fprintf(buf, ` textLength="%s" lengthAdjust="spacingAndGlyphs"`, formatSVGNumber(width))
+		}
+
+		fmt.Fprintf(buf, `>%s</text>`, html.EscapeString(text))
+	}
+
+	fmt.Fprintf(buf, "</svg>\n")
+
+	err := buf.Flush()
+
+	if err != nil {
+		return fmt.Errorf("Failed to write SVG, %w", err)
+	}
+
+	return nil
+}
+
+// svgOption returns 'v' or, if it is empty, 'fallback'.
+func svgOption(v string, fallback string) string {
+
+	if v == "" {
+		return fallback
+	}
+
+	return v
+}
+
+// svgText returns 'text' with control characters, such as the group separators of some BCBP strings, which may
+// not appear in XML documents replaced by their Unicode control pictures.
+func svgText(text string) string {
+
+	return strings.Map(func(r rune) rune {
+
+		if r < 0x20 {
+			return 0x2400 + r
+		}
+
+		if r == 0x7f {
+			return 0x2421
+		}
+
+		return r
+	}, text)
+}
+
+// formatSVGNumber formats 'v' with at most three decimal places and no trailing zeros.
+func formatSVGNumber(v float64) string {
+	return strconv.FormatFloat(float64(int64(v*1000+0.5))/1000, 'f', -1, 64)
+}
@@ -0,0 +1,257 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// testSVG is the structure of the SVG documents written by writeSVG.
+type testSVG struct {
+	ViewBox string `xml:"viewBox,attr"`
+	Width   string `xml:"width,attr"`
+	Height  string `xml:"height,attr"`
+	Rect    []struct {
+		Fill string `xml:"fill,attr"`
+	} `xml:"rect"`
+	Path struct {
+		Fill string `xml:"fill,attr"`
+		D    string `xml:"d,attr"`
+	} `xml:"path"`
+	Text []struct {
+		FontFamily string `xml:"font-family,attr"`
+		FontSize   string `xml:"font-size,attr"`
+		Fill       string `xml:"fill,attr"`
+		TextLength string `xml:"textLength,attr"`
+		Value      string `xml:",chardata"`
+	} `xml:"text"`
+}
+
+// TestWriteSVG writes a small matrix as an SVG document, using a variety of options, and ensures that the document
+// has the expected structure.
+func TestWriteSVG(t *testing.T) {
+
+	// Row 0 has a run of two dark modules and row 1 has a single dark module
+
+	m := newMatrix(3, 2)
+	m.set(0, 0, true)
+	m.set(1, 0, true)
+	m.set(2, 1, true)
+
+	render := &renderOptions{moduleSize: 2, quietZone: 1}
+
+	tests := []struct {
+		name string
+		opts *SVGOptions
+		// Whether an error is expected.
+		err      bool
+		view_box string
+		width    string
+		height   string
+		// The expected background colour, or "" if no background is expected.
+		background string
+		foreground string
+		// The expected human-readable text, or "" if no text is expected.
+		text      string
+		font_size string
+		squeezed  bool
+	}{
+		{"defaults", nil, false, "0 0 5 4", "10", "8", SVG_BACKGROUND, SVG_FOREGROUND, "", "", false},
+		{"colours", &SVGOptions{Foreground: "navy", Background: "#fafafa"}, false, "0 0 5 4", "10", "8", "#fafafa", "navy", "", "", false},
+		{"transparent", &SVGOptions{Background: "none"}, false, "0 0 5 4", "10", "8", "", SVG_FOREGROUND, "", "", false},
+		{"dimensions", &SVGOptions{Width: "80mm", Height: "60mm"}, false, "0 0 5 4", "80mm", "60mm", SVG_BACKGROUND, SVG_FOREGROUND, "", "", false},
+		{"width_only", &SVGOptions{Width: "100%"}, false, "0 0 5 4", "100%", "", SVG_BACKGROUND, SVG_FOREGROUND, "", "", false},
+		{"text", &SVGOptions{Text: "AB", FontSize: 2}, false, "0 0 5 7", "10", "14", SVG_BACKGROUND, SVG_FOREGROUND, "AB", "2", false},
+		{"text_fitted", &SVGOptions{Text: "ABC"}, false, "0 0 5 8.167", "10", "16.333", SVG_BACKGROUND, SVG_FOREGROUND, "ABC", "2.778", false},
+		{"text_squeezed", &SVGOptions{Text: "ABCDEF", FontSize: 4}, false, "0 0 5 10", "10", "20", SVG_BACKGROUND, SVG_FOREGROUND, "ABCDEF", "4", true},
+		{"control_characters", &SVGOptions{Text: "A\x1dB", FontSize: 1}, false, "0 0 5 5.5", "10", "11", SVG_BACKGROUND, SVG_FOREGROUND, "A␝B", "1", false},
+		{"escaped", &SVGOptions{Text: "<&>", Foreground: `"red"`, FontSize: 1}, false, "0 0 5 5.5", "10", "11", SVG_BACKGROUND, `"red"`, "<&>", "1", false},
+		{"invalid_width", &SVGOptions{Width: "80 mm"}, true, "", "", "", "", "", "", "", false},
+		{"invalid_height", &SVGOptions{Height: "-1"}, true, "", "", "", "", "", "", "", false},
+		{"invalid_font_size", &SVGOptions{Text: "AB", FontSize: -1}, true, "", "", "", "", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+
+		var buf bytes.Buffer
+
+		err := writeSVG(m, render, tt.opts, &buf)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to write SVG, %v", tt.name, err)
+			continue
+		}
+
+		var doc testSVG
+
+		err = xml.Unmarshal(buf.Bytes(), &doc)
+
+		if err != nil {
+			t.Errorf("%s: failed to parse SVG, %v", tt.name, err)
+			continue
+		}
+
+		if doc.ViewBox != tt.view_box || doc.Width != tt.width || doc.Height != tt.height {
+			t.Errorf("%s: expected viewBox '%s' at %sx%s, got '%s' at %sx%s", tt.name, tt.view_box, tt.width, tt.height, doc.ViewBox, doc.Width, doc.Height)
+		}
+
+		switch {
+		case tt.background == "" && len(doc.Rect) != 0:
+			t.Errorf("%s: unexpected background", tt.name)
+		case tt.background != "" && len(doc.Rect) != 1:
+			t.Errorf("%s: expected a background", tt.name)
+		case tt.background != "" && doc.Rect[0].Fill != tt.background:
+			t.Errorf("%s: expected background '%s', got '%s'", tt.name, tt.background, doc.Rect[0].Fill)
+		}
+
+		if doc.Path.Fill != tt.foreground {
+			t.Errorf("%s: expected foreground '%s', got '%s'", tt.name, tt.foreground, doc.Path.Fill)
+		}
+
+		if doc.Path.D != "M1 1h2v1h-2zM3 2h1v1h-1z" {
+			t.Errorf("%s: unexpected path '%s'", tt.name, doc.Path.D)
+		}
+
+		if tt.text == "" {
+
+			if len(doc.Text) != 0 {
+				t.Errorf("%s: unexpected text", tt.name)
+			}
+
+			continue
+		}
+
+		if len(doc.Text) != 1 {
+			t.Errorf("%s: expected text", tt.name)
+			continue
+		}
+
+		text := doc.Text[0]
+
+		if text.Value != tt.text || text.FontSize != tt.font_size {
+			t.Errorf("%s: expected text '%s' at size %s, got '%s' at size %s", tt.name, tt.text, tt.font_size, text.Value, text.FontSize)
+		}
+
+		if text.FontFamily != SVG_FONT_FAMILY || text.Fill != tt.foreground {
+			t.Errorf("%s: unexpected text font family '%s' or fill '%s'", tt.name, text.FontFamily, text.Fill)
+		}
+
+		if (text.TextLength != "") != tt.squeezed {
+			t.Errorf("%s: expected text to be squeezed %t, got textLength '%s'", tt.name, tt.squeezed, text.TextLength)
+		}
+	}
+}
+
+// TestEncodeSVG encodes BCBP strings as SVG documents, ensuring that they are sized using the module size and
+// quiet zone of each symbology and that only symbologies which can be encoded are accepted.
+func TestEncodeSVG(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		uri string
+		// Whether an error is expected.
+		err         bool
+		module_size int
+		quiet_zone  int
+	}{
+		{"pdf417://", false, PDF417_MODULE_SIZE, PDF417_QUIET_ZONE},
+		{"pdf417://?module_size=5&quiet_zone=0", false, 5, 0},
+		{"aztec://", false, AZTEC_MODULE_SIZE, AZTEC_QUIET_ZONE},
+		{"aztec://?error_correction=50&quiet_zone=4", false, AZTEC_MODULE_SIZE, 4},
+		{"qr://", true, 0, 0},
+		{"auto://", true, 0, 0},
+	}
+
+	for _, tt := range tests {
+
+		var buf bytes.Buffer
+
+		err := EncodeSVG(ctx, tt.uri, testBCBP, nil, &buf)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("'%s': expected an error", tt.uri)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("'%s': failed to encode SVG, %v", tt.uri, err)
+			continue
+		}
+
+		e, err := newSymbolEncoder(ctx, tt.uri)
+
+		if err != nil {
+			t.Errorf("'%s': failed to create encoder, %v", tt.uri, err)
+			continue
+		}
+
+		m, err := e.EncodeMatrix(testBCBP)
+
+		if err != nil {
+			t.Errorf("'%s': failed to encode matrix, %v", tt.uri, err)
+			continue
+		}
+
+		var doc testSVG
+
+		err = xml.Unmarshal(buf.Bytes(), &doc)
+
+		if err != nil {
+			t.Errorf("'%s': failed to parse SVG, %v", tt.uri, err)
+			continue
+		}
+
+		width := m.Width + tt.quiet_zone*2
+		height := m.Height + tt.quiet_zone*2
+
+		view_box := fmt.Sprintf("0 0 %d %d", width, height)
+		svg_width := strconv.Itoa(width * tt.module_size)
+		svg_height := strconv.Itoa(height * tt.module_size)
+
+		if doc.ViewBox != view_box || doc.Width != svg_width || doc.Height != svg_height {
+			t.Errorf("'%s': expected viewBox '%s' at %sx%s, got '%s' at %sx%s", tt.uri, view_box, svg_width, svg_height, doc.ViewBox, doc.Width, doc.Height)
+		}
+	}
+}
+
+// TestFormatSVGNumber formats numbers with at most three decimal places.
+func TestFormatSVGNumber(t *testing.T) {
+
+	tests := []struct {
+		v        float64
+		expected string
+	}{
+		{0, "0"},
+		{12, "12"},
+		{1.5, "1.5"},
+		{2.0 / 3.0, "0.667"},
+		{1.0004, "1"},
+		{1.0005, "1.001"},
+	}
+
+	for _, tt := range tests {
+
+		s := formatSVGNumber(tt.v)
+
+		if s != tt.expected {
+			t.Errorf("%v: expected '%s', got '%s'", tt.v, tt.expected, s)
+		}
+	}
+}
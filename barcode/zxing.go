@@ -0,0 +1,50 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/makiuchi-d/gozxing"
+)
+
+// readZXing decodes the symbol in 'im' using the gozxing reader 'r'. The image is binarized using gozxing's
+// hybrid (local) binarizer and, if that fails, its global histogram binarizer. Transparent pixels are treated as
+// if they were drawn on a white background.
+func readZXing(r gozxing.Reader, im image.Image) (string, error) {
+
+	bounds := im.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), im, bounds.Min, draw.Over)
+
+	src := gozxing.NewLuminanceSourceFromImage(flat)
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+
+	var err error
+
+	for _, binarizer := range []gozxing.Binarizer{gozxing.NewHybridBinarizer(src), gozxing.NewGlobalHistgramBinarizer(src)} {
+
+		var bmp *gozxing.BinaryBitmap
+
+		bmp, err = gozxing.NewBinaryBitmap(binarizer)
+
+		if err != nil {
+			return "", fmt.Errorf("Failed to binarize image, %w", err)
+		}
+
+		var result *gozxing.Result
+
+		result, err = r.Decode(bmp, hints)
+
+		if err == nil {
+			return result.GetText(), nil
+		}
+	}
+
+	return "", err
+}
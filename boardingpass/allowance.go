@@ -0,0 +1,152 @@
+package boardingpass
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UNIT_KILOGRAMS is the unit of baggage allowances measured in kilograms ("K" or "KG").
+const UNIT_KILOGRAMS string = "kg"
+
+// UNIT_POUNDS is the unit of baggage allowances measured in pounds ("L" or "LB").
+const UNIT_POUNDS string = "lb"
+
+var re_baggage_allowance = regexp.MustCompile(`^(?:([0-9]{1,2}) ?PC)? ?(?:([0-9]{1,3}) ?(KG|K|LB|L))?$`)
+
+var re_frequent_flyer_separators = regexp.MustCompile(`[ \-/.]`)
+
+// BaggageAllowance is the decoded "free baggage allowance" conditional item.
+type BaggageAllowance struct {
+	// The free baggage allowance as it was encoded.
+	Raw string `json:"raw"`
+	// The number of pieces allowed, if the allowance is piece based.
+	Pieces int `json:"pieces,omitempty"`
+	// The total (weight concept) or per piece (piece concept) weight allowed, if the allowance specifies a weight.
+	Weight int `json:"weight,omitempty"`
+	// The unit of 'Weight'. One of the UNIT_ constants.
+	Unit string `json:"unit,omitempty"`
+	// Whether or not the allowance is "NIL" (or "0PC"), no free baggage.
+	Nil bool `json:"nil,omitempty"`
+}
+
+// FrequentFlyer is the decoded "frequent flyer airline designator" and "frequent flyer number" conditional items.
+type FrequentFlyer struct {
+	// The airline designator of the frequent flyer programme.
+	Airline string `json:"airline"`
+	// The member number with spaces, hyphens, slashes and periods removed and, if it is followed by digits, any
+	// leading airline designator of the programme removed.
+	Number string `json:"number"`
+	// The frequent flyer number as it was encoded.
+	Raw string `json:"raw"`
+}
+
+// BaggageAllowance returns the decoded free baggage allowance of 'l' or nil if it is absent or blank. See
+// `ParseBaggageAllowance` for details.
+func (l *Leg) BaggageAllowance() (*BaggageAllowance, error) {
+
+	c := l.Conditional
+
+	if c == nil || c.FreeBaggageAllowance == nil || c.FreeBaggageAllowance.Value == "" {
+		return nil, nil
+	}
+
+	return ParseBaggageAllowance(c.FreeBaggageAllowance.Raw)
+}
+
+// FrequentFlyer returns the decoded frequent flyer details of 'l' or nil if both the airline designator and the
+// number are absent or blank. See `ParseFrequentFlyer` for details.
+func (l *Leg) FrequentFlyer() *FrequentFlyer {
+
+	c := l.Conditional
+
+	if c == nil {
+		return nil
+	}
+
+	airline := ""
+	number := ""
+
+	if c.FrequentFlyerAirlineDesignator != nil {
+		airline = c.FrequentFlyerAirlineDesignator.Value
+	}
+
+	if c.FrequentFlyerNumber != nil {
+		number = c.FrequentFlyerNumber.Raw
+	}
+
+	if airline == "" && strings.TrimSpace(number) == "" {
+		return nil
+	}
+
+	return ParseFrequentFlyer(airline, number)
+}
+
+// ParseBaggageAllowance decodes 'raw', a free baggage allowance such as "20K" (20 kilograms), "40L" (40 pounds),
+// "2PC" (two pieces) or "1PC23K" (one piece of up to 23 kilograms). "NIL" and "0PC" are decoded as no free baggage.
+func ParseBaggageAllowance(raw string) (*BaggageAllowance, error) {
+
+	a := &BaggageAllowance{
+		Raw: raw,
+	}
+
+	allowance := strings.ToUpper(strings.TrimSpace(raw))
+
+	if allowance == "NIL" {
+		a.Nil = true
+		return a, nil
+	}
+
+	m := re_baggage_allowance.FindStringSubmatch(allowance)
+
+	if m == nil || (m[1] == "" && m[2] == "") {
+		return nil, fmt.Errorf("Invalid free baggage allowance '%s'", raw)
+	}
+
+	if m[1] != "" {
+		a.Pieces, _ = strconv.Atoi(m[1])
+		a.Nil = a.Pieces == 0
+	}
+
+	if m[2] != "" {
+
+		a.Weight, _ = strconv.Atoi(m[2])
+
+		switch m[3] {
+		case "K", "KG":
+			a.Unit = UNIT_KILOGRAMS
+		default:
+			a.Unit = UNIT_POUNDS
+		}
+	}
+
+	return a, nil
+}
+
+// ParseFrequentFlyer returns the frequent flyer details for the programme of the airline 'airline' and the member
+// number 'raw'.
+func ParseFrequentFlyer(airline string, raw string) *FrequentFlyer {
+
+	airline = strings.ToUpper(strings.TrimSpace(airline))
+	number := re_frequent_flyer_separators.ReplaceAllString(strings.ToUpper(raw), "")
+
+	// Some airlines prefix the member number with the programme's airline designator
+
+	if airline != "" {
+
+		n, ok := strings.CutPrefix(number, airline)
+
+		if ok && n != "" && strings.Trim(n, "0123456789") == "" {
+			number = n
+		}
+	}
+
+	ff := &FrequentFlyer{
+		Airline: airline,
+		Number:  number,
+		Raw:     raw,
+	}
+
+	return ff
+}
@@ -0,0 +1,84 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestParseBaggageAllowance decodes weight, piece and combined free baggage allowances.
+func TestParseBaggageAllowance(t *testing.T) {
+
+	tests := []struct {
+		raw string
+		// Whether an error is expected.
+		err    bool
+		pieces int
+		weight int
+		unit   string
+		none   bool
+	}{
+		{"20K", false, 0, 20, UNIT_KILOGRAMS, false},
+		{"23KG", false, 0, 23, UNIT_KILOGRAMS, false},
+		{"40L", false, 0, 40, UNIT_POUNDS, false},
+		{"2PC", false, 2, 0, "", false},
+		{"1PC23K", false, 1, 23, UNIT_KILOGRAMS, false},
+		{"1 PC 50LB", false, 1, 50, UNIT_POUNDS, false},
+		{"0PC", false, 0, 0, "", true},
+		{"NIL", false, 0, 0, "", true},
+		{"2pc", false, 2, 0, "", false},
+		{"XYZ", true, 0, 0, "", false},
+		{"PC", true, 0, 0, "", false},
+	}
+
+	for _, tt := range tests {
+
+		a, err := ParseBaggageAllowance(tt.raw)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("'%s': expected an error", tt.raw)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("'%s': failed to parse, %v", tt.raw, err)
+			continue
+		}
+
+		if a.Pieces != tt.pieces || a.Weight != tt.weight || a.Unit != tt.unit || a.Nil != tt.none {
+			t.Errorf("'%s': expected %d pieces, %d %s (nil %t), got %d pieces, %d %s (nil %t)", tt.raw, tt.pieces, tt.weight, tt.unit, tt.none, a.Pieces, a.Weight, a.Unit, a.Nil)
+		}
+	}
+}
+
+// TestParseFrequentFlyer normalises frequent flyer numbers with separators and airline designator prefixes.
+func TestParseFrequentFlyer(t *testing.T) {
+
+	tests := []struct {
+		airline string
+		raw     string
+		number  string
+	}{
+		{"AC", "1234567890123   ", "1234567890123"},
+		{"AC", "123-456 789/0", "1234567890"},
+		{"LH ", "LH992001234567", "992001234567"},
+		{"LH", "LHABC123", "LHABC123"},
+		{"BA", "ba 12345678", "12345678"},
+		{"", "12345678", "12345678"},
+	}
+
+	for _, tt := range tests {
+
+		ff := ParseFrequentFlyer(tt.airline, tt.raw)
+
+		if ff.Number != tt.number {
+			t.Errorf("'%s' '%s': expected number '%s', got '%s'", tt.airline, tt.raw, tt.number, ff.Number)
+		}
+
+		if ff.Raw != tt.raw {
+			t.Errorf("'%s' '%s': expected raw number '%s', got '%s'", tt.airline, tt.raw, tt.raw, ff.Raw)
+		}
+	}
+}
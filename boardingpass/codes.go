@@ -0,0 +1,194 @@
+package boardingpass
+
+import (
+	"fmt"
+	"slices"
+)
+
+// codeTables are the Resolution 792 code tables for single character fields, keyed by field name and then code.
+// Blank fields are represented by a single space.
+var codeTables = map[string]map[string]string{
+	// Mandatory items
+	"electronic_ticket_indicator": {
+		"E": "Electronic ticket",
+		" ": "Not an electronic ticket",
+	},
+	"passenger_status": {
+		"0": "Ticket issuance/passenger not checked in",
+		"1": "Ticket issuance/passenger checked in",
+		"2": "Baggage checked/passenger not checked in",
+		"3": "Baggage checked/passenger checked in",
+		"4": "Passenger passed security check",
+		"5": "Passenger passed gate exit (coupon used)",
+		"6": "Transit",
+		"7": "Standby",
+		"8": "Boarding data revalidation done",
+		"9": "Original boarding line used at time of ticket issuance",
+		"A": "Up- or down-grading required at close out",
+	},
+	// Unique conditional items
+	"passenger_description": {
+		"0": "Adult",
+		"1": "Male",
+		"2": "Female",
+		"3": "Child",
+		"4": "Infant",
+		"5": "No passenger (cabin baggage)",
+		"6": "Adult travelling with infant",
+		"7": "Unaccompanied minor",
+		" ": "Not specified",
+	},
+	"source_of_checkin": {
+		"W": "Web",
+		"K": "Airport kiosk",
+		"R": "Remote or off site kiosk",
+		"M": "Mobile device",
+		"O": "Airport agent",
+		"T": "Town agent",
+		"V": "Third party vendor",
+		" ": "Not specified",
+	},
+	"source_of_boarding_pass_issuance": {
+		"W": "Web printed",
+		"K": "Airport kiosk",
+		"X": "Transfer kiosk",
+		"R": "Remote or off site kiosk",
+		"M": "Mobile device",
+		"O": "Airport agent",
+		"T": "Town agent",
+		"V": "Third party vendor",
+		" ": "Not specified",
+	},
+	"document_type": {
+		"B": "Boarding pass",
+		"I": "Itinerary receipt",
+		" ": "Not specified",
+	},
+	// Repeated conditional items
+	"selectee_indicator": {
+		"0": "Not selectee",
+		"1": "Selectee",
+		"3": "Known (low risk) passenger",
+		" ": "Not specified",
+	},
+	"international_documentation_verification": {
+		"0": "Travel document verification not required",
+		"1": "Travel document verification required",
+		"2": "Travel document verification performed",
+		" ": "Not specified",
+	},
+	"id_ad_indicator": {
+		"0": "IDN1 positive space",
+		"1": "IDN2 space available",
+		"2": "IDB1 positive space",
+		"3": "IDB2 space available",
+		"4": "AD",
+		"5": "DG",
+		"6": "DM",
+		"7": "GE",
+		"8": "IG",
+		"9": "RG",
+		"A": "UD",
+		"B": "ID (industry discount not followed by any classification)",
+		"C": "IDFS1",
+		"D": "IDFS2",
+		"E": "IDR1",
+		"F": "IDR2",
+		" ": "Not specified",
+	},
+}
+
+// CodeDescription is the human-readable description of a single character code.
+type CodeDescription struct {
+	// The code as it was encoded.
+	Code string `json:"code"`
+	// The description of the code from the Resolution 792 code tables.
+	Description string `json:"description"`
+	// Whether or not the code is defined by Resolution 792. Unknown codes are described as such rather than omitted.
+	Known bool `json:"known"`
+}
+
+// DescribeCode returns the description of 'code' in the field, or conditional item, 'field'. If 'field' is not
+// a coded field nil is returned. An empty code is treated as a blank (space) code.
+func DescribeCode(field string, code string) *CodeDescription {
+
+	table, ok := codeTables[field]
+
+	if !ok {
+		return nil
+	}
+
+	if code == "" {
+		code = " "
+	}
+
+	d := &CodeDescription{
+		Code: code,
+	}
+
+	desc, ok := table[code]
+
+	if ok {
+		d.Description = desc
+		d.Known = true
+	} else {
+		d.Description = fmt.Sprintf("Unknown %s code '%s'", field, code)
+	}
+
+	return d
+}
+
+// Descriptions returns the description of each coded mandatory field and conditional item present in 'l', keyed
+// by field name.
+func (l *Leg) Descriptions() map[string]*CodeDescription {
+
+	codes := map[string]string{
+		"electronic_ticket_indicator": l.ElectronicTicketIndicator,
+		"passenger_status":            l.PassengerStatus,
+	}
+
+	c := l.Conditional
+
+	if c != nil {
+
+		for name, i := range map[string]*Item[string]{
+			"passenger_description":                    c.PassengerDescription,
+			"source_of_checkin":                        c.SourceOfCheckIn,
+			"source_of_boarding_pass_issuance":         c.SourceOfBoardingPassIssuance,
+			"document_type":                            c.DocumentType,
+			"selectee_indicator":                       c.SelecteeIndicator,
+			"international_documentation_verification": c.InternationalDocumentationVerification,
+			"id_ad_indicator":                          c.IDADIndicator,
+		} {
+			if i != nil {
+				codes[name] = i.Raw
+			}
+		}
+	}
+
+	descriptions := make(map[string]*CodeDescription)
+
+	for name, code := range codes {
+
+		d := DescribeCode(name, code)
+
+		if d != nil {
+			descriptions[name] = d
+		}
+	}
+
+	return descriptions
+}
+
+// codeValues returns the codes defined for the field 'field', sorted.
+func codeValues(field string) []string {
+
+	values := make([]string, 0)
+
+	for code := range codeTables[field] {
+		values = append(values, code)
+	}
+
+	slices.Sort(values)
+	return values
+}
@@ -0,0 +1,94 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestDescribeCode describes known, unknown and blank codes in coded fields and ensures that fields which are not
+// coded are not described.
+func TestDescribeCode(t *testing.T) {
+
+	tests := []struct {
+		field string
+		code  string
+		// Whether a description is expected.
+		described   bool
+		known       bool
+		description string
+	}{
+		{"passenger_status", "1", true, true, "Ticket issuance/passenger checked in"},
+		{"passenger_description", "4", true, true, "Infant"},
+		{"source_of_checkin", "W", true, true, "Web"},
+		{"source_of_boarding_pass_issuance", "X", true, true, "Transfer kiosk"},
+		{"document_type", "", true, true, "Not specified"},
+		{"selectee_indicator", "3", true, true, "Known (low risk) passenger"},
+		{"id_ad_indicator", "B", true, true, "ID (industry discount not followed by any classification)"},
+		{"electronic_ticket_indicator", "X", true, false, "Unknown electronic_ticket_indicator code 'X'"},
+		{"flight_number", "0834", false, false, ""},
+	}
+
+	for _, tt := range tests {
+
+		d := DescribeCode(tt.field, tt.code)
+
+		if !tt.described {
+
+			if d != nil {
+				t.Errorf("%s '%s': unexpected description '%s'", tt.field, tt.code, d.Description)
+			}
+
+			continue
+		}
+
+		if d == nil {
+			t.Errorf("%s '%s': expected a description", tt.field, tt.code)
+			continue
+		}
+
+		if d.Known != tt.known || d.Description != tt.description {
+			t.Errorf("%s '%s': expected '%s' (known %t), got '%s' (known %t)", tt.field, tt.code, tt.description, tt.known, d.Description, d.Known)
+		}
+	}
+}
+
+// TestDescriptions describes the coded fields of a parsed leg, including its conditional items.
+func TestDescriptions(t *testing.T) {
+
+	raw := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z"
+
+	b, err := Unmarshal(raw)
+
+	if err != nil {
+		t.Fatalf("Failed to parse, %v", err)
+	}
+
+	expected := map[string]string{
+		"electronic_ticket_indicator":              "E",
+		"passenger_status":                         "1",
+		"passenger_description":                    "1",
+		"source_of_checkin":                        "W",
+		"source_of_boarding_pass_issuance":         "W",
+		"document_type":                            "B",
+		"selectee_indicator":                       " ",
+		"international_documentation_verification": "1",
+		"id_ad_indicator":                          " ",
+	}
+
+	descriptions := b.Legs[0].Descriptions()
+
+	if len(descriptions) != len(expected) {
+		t.Errorf("Expected %d descriptions, got %d", len(expected), len(descriptions))
+	}
+
+	for field, code := range expected {
+
+		d, ok := descriptions[field]
+
+		switch {
+		case !ok:
+			t.Errorf("Expected a description for %s", field)
+		case d.Code != code || !d.Known:
+			t.Errorf("Expected %s to be known code '%s', got '%s' (known %t)", field, code, d.Code, d.Known)
+		}
+	}
+}
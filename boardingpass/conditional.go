@@ -0,0 +1,498 @@
+package boardingpass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// Item is a single conditional item, decoded as 'Value' alongside the 'Raw' (untrimmed) characters it was derived from.
+type Item[T any] struct {
+	Value T      `json:"value"`
+	Raw   string `json:"raw"`
+}
+
+// IssueDate is the decoded "date of issue of boarding pass" conditional item.
+type IssueDate struct {
+	// The last digit of the year the boarding pass was issued.
+	YearDigit int `json:"year_digit"`
+	// The day of the year the boarding pass was issued.
+	DayOfYear int `json:"day_of_year"`
+}
+
+// BaggageTag is a decoded baggage tag license plate number conditional item.
+type BaggageTag struct {
+	// "0" for interline tags, "1" for fall-back tags, "2" for interline rush tags.
+	LeadingDigit string `json:"leading_digit"`
+	// The numeric code of the carrier that issued the tag.
+	AirlineNumericCode string `json:"airline_numeric_code"`
+	// The carrier's initial tag number.
+	SerialNumber string `json:"serial_number"`
+	// The number of consecutive tags that follow the initial tag number.
+	ConsecutiveTags int `json:"consecutive_tags"`
+}
+
+// Conditional is the decoded conditional section of a leg.
+type Conditional struct {
+	VersionNumber                          *Item[int]        `json:"version_number,omitempty"`
+	UniqueFieldSize                        *Item[int]        `json:"unique_field_size,omitempty"`
+	PassengerDescription                   *Item[string]     `json:"passenger_description,omitempty"`
+	SourceOfCheckIn                        *Item[string]     `json:"source_of_checkin,omitempty"`
+	SourceOfBoardingPassIssuance           *Item[string]     `json:"source_of_boarding_pass_issuance,omitempty"`
+	DateOfIssue                            *Item[IssueDate]  `json:"date_of_issue,omitempty"`
+	DocumentType                           *Item[string]     `json:"document_type,omitempty"`
+	AirlineDesignatorOfIssuer              *Item[string]     `json:"airline_designator_of_issuer,omitempty"`
+	BaggageTagLicensePlate                 *Item[BaggageTag] `json:"baggage_tag_license_plate,omitempty"`
+	FirstNonConsecutiveBaggageTag          *Item[BaggageTag] `json:"first_non_consecutive_baggage_tag,omitempty"`
+	SecondNonConsecutiveBaggageTag         *Item[BaggageTag] `json:"second_non_consecutive_baggage_tag,omitempty"`
+	RepeatedFieldSize                      *Item[int]        `json:"repeated_field_size,omitempty"`
+	AirlineNumericCode                     *Item[string]     `json:"airline_numeric_code,omitempty"`
+	DocumentSerialNumber                   *Item[string]     `json:"document_serial_number,omitempty"`
+	SelecteeIndicator                      *Item[string]     `json:"selectee_indicator,omitempty"`
+	InternationalDocumentationVerification *Item[string]     `json:"international_documentation_verification,omitempty"`
+	MarketingCarrierDesignator             *Item[string]     `json:"marketing_carrier_designator,omitempty"`
+	FrequentFlyerAirlineDesignator         *Item[string]     `json:"frequent_flyer_airline_designator,omitempty"`
+	FrequentFlyerNumber                    *Item[string]     `json:"frequent_flyer_number,omitempty"`
+	IDADIndicator                          *Item[string]     `json:"id_ad_indicator,omitempty"`
+	FreeBaggageAllowance                   *Item[string]     `json:"free_baggage_allowance,omitempty"`
+	FastTrack                              *Item[bool]       `json:"fast_track,omitempty"`
+	// Any data following the structured conditional items, reserved for individual airline use.
+	AirlineData string `json:"airline_data,omitempty"`
+}
+
+// ParseConditional decodes 'raw' as the variable size field of a leg. 'raw' is expected to have already been
+// truncated to the length declared by the leg's "field size of variable size field". If 'first' is true then
+// 'raw' is assumed to belong to the first leg of a BCBP string and may contain the unique conditional items.
+func ParseConditional(raw string, first bool) (*Conditional, error) {
+	d := &decoder{}
+	return d.parseConditional(raw, 0, first)
+}
+
+// parseConditional decodes 'raw' as the variable size field of a leg, where 'base' is the offset of 'raw' in
+// the BCBP string.
+func (d *decoder) parseConditional(raw string, base int, first bool) (*Conditional, error) {
+
+	c := &Conditional{}
+	r := &fieldReader{raw: raw, base: base}
+
+	if first {
+
+		if !strings.HasPrefix(raw, BEGINNING_OF_VERSION_NUMBER_MARKER) {
+			c.AirlineData = d.airlineData(r)
+			return c, nil
+		}
+
+		d.locate("beginning_of_version_number", r.position(), r.next(BEGINNING_OF_VERSION_NUMBER), BEGINNING_OF_VERSION_NUMBER_MARKER)
+
+		v, err := decodeItem(d, r, VERSION_NUMBER, "version_number", intItem)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.VersionNumber = v
+
+		// Subsequent legs are decoded using the version declared by the first leg
+
+		if v != nil {
+			d.version = v.Value
+		}
+
+		unique, sz, err := d.section(r, UNIQUE_FIELD_SIZE, "unique_field_size")
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read unique conditional items, %w", err)
+		}
+
+		c.UniqueFieldSize = sz
+
+		err = d.parseUnique(c, unique, r.base+r.offset-len(unique))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.remaining() > 0 {
+
+		repeated, sz, err := d.section(r, REPEATED_FIELD_SIZE, "repeated_field_size")
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read repeated conditional items, %w", err)
+		}
+
+		c.RepeatedFieldSize = sz
+
+		err = d.parseRepeated(c, repeated, r.base+r.offset-len(repeated))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.AirlineData = d.airlineData(r)
+	return c, nil
+}
+
+// airlineData reads the remaining characters in 'r' as data for individual airline use.
+func (d *decoder) airlineData(r *fieldReader) string {
+
+	offset := r.position()
+	raw := r.rest()
+
+	if raw != "" {
+		d.locate("airline_data", offset, raw, raw)
+	}
+
+	return raw
+}
+
+// stringItem reads the next (up to) 'length' characters from 'r' as a string item.
+func (d *decoder) stringItem(r *fieldReader, length int, field string) *Item[string] {
+
+	// stringItem never returns an error
+	i, _ := decodeItem(d, r, length, field, stringItem)
+	return i
+}
+
+func (d *decoder) parseUnique(c *Conditional, raw string, base int) error {
+
+	r := &fieldReader{raw: raw, base: base}
+
+	c.PassengerDescription = d.stringItem(r, PASSENGER_DESCRIPTION, "passenger_description")
+	c.SourceOfCheckIn = d.stringItem(r, SOURCE_OF_CHECK_IN, "source_of_checkin")
+	c.SourceOfBoardingPassIssuance = d.stringItem(r, SOURCE_OF_BOARDING_PASS_ISSUANCE, "source_of_boarding_pass_issuance")
+
+	dt, err := decodeItem(d, r, DATE_OF_ISSUE, "date_of_issue", issueDateItem)
+
+	if err != nil {
+		return err
+	}
+
+	c.DateOfIssue = dt
+
+	c.DocumentType = d.stringItem(r, DOCUMENT_TYPE, "document_type")
+	c.AirlineDesignatorOfIssuer = d.stringItem(r, AIRLINE_DESIGNATOR_OF_ISSUER, "airline_designator_of_issuer")
+
+	tags := []struct {
+		name string
+		item **Item[BaggageTag]
+	}{
+		{"baggage_tag_license_plate", &c.BaggageTagLicensePlate},
+		{"first_non_consecutive_baggage_tag", &c.FirstNonConsecutiveBaggageTag},
+		{"second_non_consecutive_baggage_tag", &c.SecondNonConsecutiveBaggageTag},
+	}
+
+	for _, t := range tags {
+
+		tag, err := decodeItem(d, r, BAGGAGE_TAG_LICENSE_PLATE, t.name, baggageTagItem)
+
+		if err != nil {
+			return err
+		}
+
+		*t.item = tag
+	}
+
+	return nil
+}
+
+func (d *decoder) parseRepeated(c *Conditional, raw string, base int) error {
+
+	r := &fieldReader{raw: raw, base: base}
+
+	c.AirlineNumericCode = d.stringItem(r, AIRLINE_NUMERIC_CODE, "airline_numeric_code")
+	c.DocumentSerialNumber = d.stringItem(r, DOCUMENT_SERIAL_NUMBER, "document_serial_number")
+	c.SelecteeIndicator = d.stringItem(r, SELECTEE_INDICATOR, "selectee_indicator")
+	c.InternationalDocumentationVerification = d.stringItem(r, INTERNATIONAL_DOCUMENTATION_VERIFICATION, "international_documentation_verification")
+	c.MarketingCarrierDesignator = d.stringItem(r, MARKETING_CARRIER_DESIGNATOR, "marketing_carrier_designator")
+	c.FrequentFlyerAirlineDesignator = d.stringItem(r, FREQUENT_FLYER_AIRLINE_DESIGNATOR, "frequent_flyer_airline_designator")
+	c.FrequentFlyerNumber = d.stringItem(r, FREQUENT_FLYER_NUMBER, "frequent_flyer_number")
+	c.IDADIndicator = d.stringItem(r, ID_AD_INDICATOR, "id_ad_indicator")
+	c.FreeBaggageAllowance = d.stringItem(r, FREE_BAGGAGE_ALLOWANCE, "free_baggage_allowance")
+
+	ft, err := decodeItem(d, r, FAST_TRACK, "fast_track", fastTrackItem)
+
+	if err != nil {
+		return err
+	}
+
+	c.FastTrack = ft
+	return nil
+}
+
+// decodeItem reads the next (up to) 'length' characters from 'r' and decodes them using 'decode_func'. If the item
+// can not be decoded and 'd' is lenient an item containing only the raw characters is returned.
+func decodeItem[T any](d *decoder, r *fieldReader, length int, field string, decode_func func(string) (*Item[T], error)) (*Item[T], error) {
+
+	offset := r.position()
+	raw := r.next(length)
+
+	i, err := decode_func(raw)
+
+	if err != nil {
+
+		err = d.recover(fmt.Errorf("Failed to parse %s, %w", field, err), WARNING_ITEM_INVALID, field, offset, raw)
+
+		if err != nil {
+			return nil, err
+		}
+
+		i = &Item[T]{
+			Raw: raw,
+		}
+	}
+
+	if i != nil {
+		d.locate(field, offset, raw, i.Value)
+		d.checkItemVersion(field, offset, raw)
+	}
+
+	return i, nil
+}
+
+// section reads a 'header_length' character hexadecimal field size from 'r' followed by that many characters.
+func (d *decoder) section(r *fieldReader, header_length int, field string) (string, *Item[int], error) {
+
+	offset := r.position()
+	sz_raw := r.next(header_length)
+
+	if len(sz_raw) != header_length {
+
+		err := d.recover(fmt.Errorf("Incomplete field size '%s'", sz_raw), WARNING_FIELD_SIZE_INVALID, field, offset, sz_raw)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return "", &Item[int]{Raw: sz_raw}, nil
+	}
+
+	sz, err := ParseFieldSize(sz_raw)
+
+	if err != nil {
+
+		err = d.recover(err, WARNING_FIELD_SIZE_INVALID, field, offset, sz_raw)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		sz = r.remaining()
+	}
+
+	if sz > r.remaining() {
+
+		err := d.recover(fmt.Errorf("Field size (%d) exceeds remaining data (%d)", sz, r.remaining()), WARNING_FIELD_SIZE_MISMATCH, field, offset, sz_raw)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		sz = r.remaining()
+	}
+
+	d.locate(field, offset, sz_raw, sz)
+	return r.next(sz), &Item[int]{Value: sz, Raw: sz_raw}, nil
+}
+
+// fieldReader reads successive fixed-length fields from a string.
+type fieldReader struct {
+	raw    string
+	offset int
+	// The offset of 'raw' in the BCBP string.
+	base int
+}
+
+// next returns the next (up to) 'length' characters. Conditional items may be truncated at the end of
+// a section so fewer than 'length' characters (or none at all) may be returned.
+func (r *fieldReader) next(length int) string {
+
+	start := min(r.offset, len(r.raw))
+	end := min(r.offset+length, len(r.raw))
+
+	r.offset += length
+	return r.raw[start:end]
+}
+
+// position returns the offset of the next field in the BCBP string.
+func (r *fieldReader) position() int {
+	return r.base + r.offset
+}
+
+func (r *fieldReader) remaining() int {
+	return max(len(r.raw)-r.offset, 0)
+}
+
+func (r *fieldReader) rest() string {
+	return r.next(r.remaining())
+}
+
+// ParseFieldSize parses 'raw' as a hexadecimal field size.
+func ParseFieldSize(raw string) (int, error) {
+
+	sz, err := strconv.ParseUint(raw, 16, 8)
+
+	if err != nil {
+		return -1, fmt.Errorf("Invalid field size '%s', %w", raw, err)
+	}
+
+	return int(sz), nil
+}
+
+func stringItem(raw string) (*Item[string], error) {
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	i := &Item[string]{
+		Value: strings.TrimSpace(raw),
+		Raw:   raw,
+	}
+
+	return i, nil
+}
+
+func intItem(raw string) (*Item[int], error) {
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	i := &Item[int]{
+		Raw: raw,
+	}
+
+	v := strings.TrimSpace(raw)
+
+	if v == "" {
+		return i, nil
+	}
+
+	n, err := strconv.Atoi(v)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid number '%s', %w", raw, err)
+	}
+
+	i.Value = n
+	return i, nil
+}
+
+func issueDateItem(raw string) (*Item[IssueDate], error) {
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	i := &Item[IssueDate]{
+		Raw: raw,
+	}
+
+	if strings.TrimSpace(raw) == "" {
+		return i, nil
+	}
+
+	if len(raw) != DATE_OF_ISSUE {
+		return nil, fmt.Errorf("Incomplete date of issue '%s'", raw)
+	}
+
+	y, err := strconv.Atoi(raw[0:1])
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid year digit '%s', %w", raw[0:1], err)
+	}
+
+	d, err := strconv.Atoi(raw[1:4])
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid day of year '%s', %w", raw[1:4], err)
+	}
+
+	i.Value = IssueDate{
+		YearDigit: y,
+		DayOfYear: d,
+	}
+
+	return i, nil
+}
+
+func baggageTagItem(raw string) (*Item[BaggageTag], error) {
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	i := &Item[BaggageTag]{
+		Raw: raw,
+	}
+
+	if strings.TrimSpace(raw) == "" {
+		return i, nil
+	}
+
+	if len(raw) != BAGGAGE_TAG_LICENSE_PLATE {
+		return nil, fmt.Errorf("Incomplete baggage tag license plate number '%s'", raw)
+	}
+
+	count, err := strconv.Atoi(raw[10:13])
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid number of consecutive tags '%s', %w", raw[10:13], err)
+	}
+
+	i.Value = BaggageTag{
+		LeadingDigit:       raw[0:1],
+		AirlineNumericCode: raw[1:4],
+		SerialNumber:       raw[4:10],
+		ConsecutiveTags:    count,
+	}
+
+	return i, nil
+}
+
+func fastTrackItem(raw string) (*Item[bool], error) {
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	i := &Item[bool]{
+		Raw: raw,
+	}
+
+	switch raw {
+	case "Y":
+		i.Value = true
+	case "N", " ":
+		// pass
+	default:
+		return nil, fmt.Errorf("Invalid fast track indicator '%s'", raw)
+	}
+
+	return i, nil
+}
+
+// VariableSizeField returns the variable size field of 'l' truncated to the length declared by its
+// "field size of variable size field".
+func VariableSizeField(l *bcbp.Leg) (string, error) {
+
+	if l.OptionalDataSize == "" {
+		return "", nil
+	}
+
+	sz, err := ParseFieldSize(l.OptionalDataSize)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse field size of variable size field, %w", err)
+	}
+
+	if sz > len(l.OptionalData) {
+		return "", fmt.Errorf("Field size of variable size field (%d) exceeds optional data (%d)", sz, len(l.OptionalData))
+	}
+
+	return l.OptionalData[0:sz], nil
+}
@@ -0,0 +1,144 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestParseConditional decodes the variable size field of first and subsequent legs, including sections which have
+// been truncated or omitted, and ensures that malformed items are rejected.
+func TestParseConditional(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		raw   string
+		first bool
+		// Whether an error is expected.
+		err bool
+		// The expected version number, passenger description, date of issue, number of consecutive baggage tags,
+		// frequent flyer number, fast track indicator and airline data.
+		version          int
+		description      string
+		date_of_issue    IssueDate
+		consecutive_tags int
+		frequent_flyer   string
+		fast_track       bool
+		airline_data     string
+	}{
+		{"first_leg", ">6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", true, false, 6, "1", IssueDate{6, 225}, 3, "1234567890123", true, "LX58Z"},
+		{"subsequent_leg", "2A0140987654321 1AC AC 1234567890123    2PCNWQ", false, false, 0, "", IssueDate{}, 0, "1234567890123", false, "WQ"},
+		{"no_version_number", "LX58Z", true, false, 0, "", IssueDate{}, 0, "", false, "LX58Z"},
+		{"truncated_unique", ">50A1WW6225BAC", true, false, 5, "1", IssueDate{6, 225}, 0, "", false, ""},
+		{"truncated_repeated", ">50A1WW6225BAC0D0140987654321", true, false, 5, "1", IssueDate{6, 225}, 0, "", false, ""},
+		{"empty", "", true, false, 0, "", IssueDate{}, 0, "", false, ""},
+		{"invalid_version_number", ">X181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", true, true, 0, "", IssueDate{}, 0, "", false, ""},
+		{"invalid_date_of_issue", ">5181WW62X5BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", true, true, 0, "", IssueDate{}, 0, "", false, ""},
+		{"invalid_field_size", ">5XX1WW6225BAC 0014123456003", true, true, 0, "", IssueDate{}, 0, "", false, ""},
+		{"field_size_too_long", "2B0140987654321 1AC AC 1234567890123    2PC", false, true, 0, "", IssueDate{}, 0, "", false, ""},
+		{"invalid_fast_track", "2A0140987654321 1AC AC 1234567890123    2PCX", false, true, 0, "", IssueDate{}, 0, "", false, ""},
+	}
+
+	for _, tt := range tests {
+
+		c, err := ParseConditional(tt.raw, tt.first)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to parse, %v", tt.name, err)
+			continue
+		}
+
+		if c.VersionNumber != nil && c.VersionNumber.Value != tt.version {
+			t.Errorf("%s: expected version %d, got %d", tt.name, tt.version, c.VersionNumber.Value)
+		}
+
+		if c.VersionNumber == nil && tt.version != 0 {
+			t.Errorf("%s: expected version %d", tt.name, tt.version)
+		}
+
+		if itemValue(c.PassengerDescription) != tt.description {
+			t.Errorf("%s: expected passenger description '%s', got '%s'", tt.name, tt.description, itemValue(c.PassengerDescription))
+		}
+
+		if c.DateOfIssue != nil && c.DateOfIssue.Value != tt.date_of_issue {
+			t.Errorf("%s: expected date of issue %v, got %v", tt.name, tt.date_of_issue, c.DateOfIssue.Value)
+		}
+
+		if c.BaggageTagLicensePlate != nil && c.BaggageTagLicensePlate.Value.ConsecutiveTags != tt.consecutive_tags {
+			t.Errorf("%s: expected %d consecutive tags, got %d", tt.name, tt.consecutive_tags, c.BaggageTagLicensePlate.Value.ConsecutiveTags)
+		}
+
+		if itemValue(c.FrequentFlyerNumber) != tt.frequent_flyer {
+			t.Errorf("%s: expected frequent flyer number '%s', got '%s'", tt.name, tt.frequent_flyer, itemValue(c.FrequentFlyerNumber))
+		}
+
+		if c.FastTrack != nil && c.FastTrack.Value != tt.fast_track {
+			t.Errorf("%s: expected fast track %t, got %t", tt.name, tt.fast_track, c.FastTrack.Value)
+		}
+
+		if c.AirlineData != tt.airline_data {
+			t.Errorf("%s: expected airline data '%s', got '%s'", tt.name, tt.airline_data, c.AirlineData)
+		}
+	}
+}
+
+// TestBaggageTag decodes baggage tag license plate numbers, including blank and incomplete numbers.
+func TestBaggageTag(t *testing.T) {
+
+	tests := []struct {
+		name string
+		raw  string
+		err  bool
+		tag  BaggageTag
+	}{
+		{"interline", "0014123456003", false, BaggageTag{"0", "014", "123456", 3}},
+		{"rush", "2220987654000", false, BaggageTag{"2", "220", "987654", 0}},
+		{"blank", "             ", false, BaggageTag{}},
+		{"incomplete", "001412345", true, BaggageTag{}},
+		{"invalid_count", "00141234560X3", true, BaggageTag{}},
+	}
+
+	for _, tt := range tests {
+
+		i, err := baggageTagItem(tt.raw)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to decode, %v", tt.name, err)
+			continue
+		}
+
+		if i.Value != tt.tag {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.tag, i.Value)
+		}
+
+		if i.Raw != tt.raw {
+			t.Errorf("%s: expected raw characters '%s', got '%s'", tt.name, tt.raw, i.Raw)
+		}
+	}
+}
+
+// itemValue returns the value of 'i' or "" if 'i' is nil.
+func itemValue(i *Item[string]) string {
+
+	if i == nil {
+		return ""
+	}
+
+	return i.Value
+}
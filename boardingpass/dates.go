@@ -0,0 +1,231 @@
+package boardingpass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DATE_SOURCE_DATE_OF_ISSUE indicates that the year of a flight was inferred from the date of issue of the
+// boarding pass.
+const DATE_SOURCE_DATE_OF_ISSUE string = "date_of_issue"
+
+// DATE_SOURCE_REFERENCE_DATE indicates that the year of a flight was inferred from a reference date.
+const DATE_SOURCE_REFERENCE_DATE string = "reference_date"
+
+// AMBIGUITY_LOW indicates that the year of a flight was derived from the date of issue or that the resolved date
+// is within a month of the reference date.
+const AMBIGUITY_LOW string = "low"
+
+// AMBIGUITY_MEDIUM indicates that the resolved date is within five months of the reference date.
+const AMBIGUITY_MEDIUM string = "medium"
+
+// AMBIGUITY_HIGH indicates that the resolved date is (nearly) as far from the reference date as the same day
+// of year in an adjacent year.
+const AMBIGUITY_HIGH string = "high"
+
+// ISO_8601_DATE is the layout used to format resolved dates.
+const ISO_8601_DATE string = "2006-01-02"
+
+// FlightDate is the calendar date derived from the three digit day of year in a leg's date of flight.
+type FlightDate struct {
+	// The date of the flight as an ISO-8601 (YYYY-MM-DD) string.
+	Date      string `json:"date"`
+	Year      int    `json:"year"`
+	Month     int    `json:"month"`
+	Day       int    `json:"day"`
+	DayOfYear int    `json:"day_of_year"`
+	// How the year was inferred. One of the DATE_SOURCE_ constants.
+	Source string `json:"source"`
+	// How ambiguous the inferred year is. One of the AMBIGUITY_ constants.
+	Ambiguity string `json:"ambiguity"`
+	// The other dates, in adjacent years, that the day of year may refer to.
+	Alternatives []string `json:"alternatives,omitempty"`
+}
+
+// IssueDate returns the date of issue of the boarding pass, encoded in the unique conditional items of the first
+// leg, or nil if it is absent or blank.
+func (b *BCBP) IssueDate() *IssueDate {
+
+	if len(b.Legs) == 0 || b.Legs[0].Conditional == nil {
+		return nil
+	}
+
+	i := b.Legs[0].Conditional.DateOfIssue
+
+	if i == nil || i.Value.DayOfYear == 0 {
+		return nil
+	}
+
+	return &i.Value
+}
+
+// FlightDate resolves the date of flight of the leg at index 'idx' to a calendar date. See `ResolveFlightDate`
+// for details.
+func (b *BCBP) FlightDate(idx int, reference time.Time) (*FlightDate, error) {
+
+	if idx < 0 || idx >= len(b.Legs) {
+		return nil, fmt.Errorf("Invalid leg index %d", idx)
+	}
+
+	return ResolveFlightDate(b.Legs[idx].DateOfFlight, b.IssueDate(), reference)
+}
+
+// ResolveFlightDate resolves 'date_of_flight', a three digit day of year, to a calendar date. If 'issued' is not
+// nil the year is inferred from the date of issue: the most recent year, on or before 'reference', ending in the
+// year digit of the date of issue, or the year after if the day of the flight precedes the day of issue. Otherwise
+// the year is the one which places the day of year closest to 'reference'.
+func ResolveFlightDate(date_of_flight string, issued *IssueDate, reference time.Time) (*FlightDate, error) {
+
+	day, err := strconv.Atoi(strings.TrimSpace(date_of_flight))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse date of flight '%s', %w", date_of_flight, err)
+	}
+
+	if day < 1 || day > 366 {
+		return nil, fmt.Errorf("Date of flight must be between 001 and 366, got '%s'", date_of_flight)
+	}
+
+	ref := time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, time.UTC)
+
+	if issued != nil {
+
+		fd, ok := resolveFromIssueDate(day, issued, ref)
+
+		if ok {
+			return fd, nil
+		}
+	}
+
+	return resolveFromReference(day, ref), nil
+}
+
+// resolveFromIssueDate resolves 'day' relative to the date of issue 'issued'. It returns false if the year can
+// not be inferred, for example if the day of year does not exist in the inferred year.
+func resolveFromIssueDate(day int, issued *IssueDate, ref time.Time) (*FlightDate, bool) {
+
+	year := ref.Year() - mod(ref.Year()-issued.YearDigit, 10)
+
+	issue_t, ok := dayOfYear(year, issued.DayOfYear)
+
+	if ok && issue_t.After(ref) {
+		year -= 10
+		_, ok = dayOfYear(year, issued.DayOfYear)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	if day < issued.DayOfYear {
+		year += 1
+	}
+
+	t, ok := dayOfYear(year, day)
+
+	if !ok {
+		return nil, false
+	}
+
+	fd := newFlightDate(t, day, DATE_SOURCE_DATE_OF_ISSUE, AMBIGUITY_LOW)
+	return fd, true
+}
+
+// resolveFromReference resolves 'day' to the date closest to 'ref'.
+func resolveFromReference(day int, ref time.Time) *FlightDate {
+
+	candidates := make([]time.Time, 0)
+
+	for _, y := range []int{ref.Year() - 1, ref.Year(), ref.Year() + 1} {
+
+		t, ok := dayOfYear(y, day)
+
+		if ok {
+			candidates = append(candidates, t)
+		}
+	}
+
+	// Day 366 only exists in leap years
+
+	for offset := 2; len(candidates) == 0; offset++ {
+
+		for _, y := range []int{ref.Year() - offset, ref.Year() + offset} {
+
+			t, ok := dayOfYear(y, day)
+
+			if ok {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+
+	best := 0
+
+	for idx, t := range candidates {
+
+		if absDays(t, ref) < absDays(candidates[best], ref) {
+			best = idx
+		}
+	}
+
+	distance := absDays(candidates[best], ref)
+
+	ambiguity := AMBIGUITY_HIGH
+
+	switch {
+	case distance <= 31:
+		ambiguity = AMBIGUITY_LOW
+	case distance <= 150:
+		ambiguity = AMBIGUITY_MEDIUM
+	}
+
+	fd := newFlightDate(candidates[best], day, DATE_SOURCE_REFERENCE_DATE, ambiguity)
+
+	for idx, t := range candidates {
+
+		if idx != best {
+			fd.Alternatives = append(fd.Alternatives, t.Format(ISO_8601_DATE))
+		}
+	}
+
+	return fd
+}
+
+func newFlightDate(t time.Time, day int, source string, ambiguity string) *FlightDate {
+
+	fd := &FlightDate{
+		Date:      t.Format(ISO_8601_DATE),
+		Year:      t.Year(),
+		Month:     int(t.Month()),
+		Day:       t.Day(),
+		DayOfYear: day,
+		Source:    source,
+		Ambiguity: ambiguity,
+	}
+
+	return fd
+}
+
+// dayOfYear returns the date of 'day' in 'year' or false if 'year' has fewer days.
+func dayOfYear(year int, day int) (time.Time, bool) {
+
+	t := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day-1)
+	return t, t.Year() == year
+}
+
+func absDays(a time.Time, b time.Time) int {
+
+	d := int(a.Sub(b).Hours() / 24)
+
+	if d < 0 {
+		d = -d
+	}
+
+	return d
+}
+
+func mod(a int, b int) int {
+	return ((a % b) + b) % b
+}
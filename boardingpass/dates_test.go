@@ -0,0 +1,73 @@
+package boardingpass
+
+import (
+	"testing"
+	"time"
+)
+
+// TestResolveFlightDate resolves days of year, with and without a date of issue, relative to reference dates
+// either side of a year boundary.
+func TestResolveFlightDate(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		date_of_flight string
+		issued         *IssueDate
+		reference      string
+		// Whether an error is expected.
+		err bool
+		// The expected date, source and ambiguity.
+		date      string
+		source    string
+		ambiguity string
+	}{
+		{"next_year", "002", nil, "2025-12-30", false, "2026-01-02", DATE_SOURCE_REFERENCE_DATE, AMBIGUITY_LOW},
+		{"previous_year", "364", nil, "2026-01-02", false, "2025-12-30", DATE_SOURCE_REFERENCE_DATE, AMBIGUITY_LOW},
+		{"same_year", "180", nil, "2026-03-01", false, "2026-06-29", DATE_SOURCE_REFERENCE_DATE, AMBIGUITY_MEDIUM},
+		{"leap_day", "366", nil, "2025-06-01", false, "2024-12-31", DATE_SOURCE_REFERENCE_DATE, AMBIGUITY_HIGH},
+		{"issued_previous_year", "003", &IssueDate{5, 360}, "2026-01-05", false, "2026-01-03", DATE_SOURCE_DATE_OF_ISSUE, AMBIGUITY_LOW},
+		{"issued_same_year", "361", &IssueDate{5, 360}, "2026-01-05", false, "2025-12-27", DATE_SOURCE_DATE_OF_ISSUE, AMBIGUITY_LOW},
+		{"issued_after_reference", "005", &IssueDate{6, 2}, "2026-01-01", false, "2016-01-05", DATE_SOURCE_DATE_OF_ISSUE, AMBIGUITY_LOW},
+		{"issued_leap_day", "002", &IssueDate{5, 366}, "2025-12-30", false, "2026-01-02", DATE_SOURCE_REFERENCE_DATE, AMBIGUITY_LOW},
+		{"zero", "000", nil, "2026-01-01", true, "", "", ""},
+		{"too_large", "367", nil, "2026-01-01", true, "", "", ""},
+		{"not_a_number", "ABC", nil, "2026-01-01", true, "", "", ""},
+	}
+
+	for _, tt := range tests {
+
+		ref, err := time.Parse(ISO_8601_DATE, tt.reference)
+
+		if err != nil {
+			t.Fatalf("%s: failed to parse reference date, %v", tt.name, err)
+		}
+
+		fd, err := ResolveFlightDate(tt.date_of_flight, tt.issued, ref)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to resolve date of flight, %v", tt.name, err)
+			continue
+		}
+
+		if fd.Date != tt.date {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.date, fd.Date)
+		}
+
+		if fd.Source != tt.source {
+			t.Errorf("%s: expected source %s, got %s", tt.name, tt.source, fd.Source)
+		}
+
+		if fd.Ambiguity != tt.ambiguity {
+			t.Errorf("%s: expected %s ambiguity, got %s", tt.name, tt.ambiguity, fd.Ambiguity)
+		}
+	}
+}
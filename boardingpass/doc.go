@@ -0,0 +1,4 @@
+// Package boardingpass provides methods for working with the parts of an IATA Resolution 792 (BCBP)
+// string that the sfomuseum/go-bcbp package does not decode, namely the conditional section of each leg and
+// the security data section.
+package boardingpass
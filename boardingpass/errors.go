@@ -0,0 +1,77 @@
+package boardingpass
+
+// Problems which would be repaired in lenient mode are returned as errors, in strict mode, using the same code
+// as the corresponding warning. The following codes are only used for errors.
+
+// ERROR_EMPTY_INPUT is returned when the BCBP string is empty or contains no legs.
+const ERROR_EMPTY_INPUT string = "EMPTY_INPUT"
+
+// ERROR_INPUT_TOO_SHORT is returned when the BCBP string is shorter than the mandatory items of a single leg.
+const ERROR_INPUT_TOO_SHORT string = "INPUT_TOO_SHORT"
+
+// ERROR_FIELD_INVALID is returned when a field can not be parsed.
+const ERROR_FIELD_INVALID string = "FIELD_INVALID"
+
+// ERROR_FIELD_OVERFLOW is returned when a value is too long to be encoded in its fixed-width field.
+const ERROR_FIELD_OVERFLOW string = "FIELD_OVERFLOW"
+
+// ERROR_LOSSLESS_MISMATCH is returned when a BCBP string parsed in lossless mode can not be re-encoded byte for byte.
+const ERROR_LOSSLESS_MISMATCH string = "LOSSLESS_MISMATCH"
+
+// ERROR_INTERNAL is returned when parsing a BCBP string triggers an unexpected error (a panic).
+const ERROR_INTERNAL string = "INTERNAL_ERROR"
+
+// Error describes a problem that prevented a BCBP string from being parsed.
+type Error struct {
+	// A machine-readable code for the problem. One of the ERROR_ or WARNING_ constants.
+	Code string `json:"code"`
+	// The name of the field, or conditional item, where the problem was found. This may be empty if the problem
+	// is not specific to a field.
+	Field string `json:"field"`
+	// The offset of the problem in the BCBP string or -1 if the problem is not specific to a location.
+	Offset int `json:"offset"`
+	// The index of the leg where the problem was found or -1 if the problem is not specific to a leg.
+	LegIndex int `json:"leg_index"`
+	// The raw characters where the problem was found.
+	Raw string `json:"raw"`
+	err error
+}
+
+// Error returns the error message for 'e'.
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error for 'e'.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// newError returns a new `Error` which is not specific to a field, location or leg.
+func newError(err error, code string, raw string) *Error {
+
+	e := &Error{
+		Code:     code,
+		Offset:   -1,
+		LegIndex: -1,
+		Raw:      raw,
+		err:      err,
+	}
+
+	return e
+}
+
+// fail returns a new `Error` for the leg currently being decoded.
+func (d *decoder) fail(err error, code string, field string, offset int, raw string) *Error {
+
+	e := &Error{
+		Code:     code,
+		Field:    field,
+		Offset:   offset,
+		LegIndex: d.leg,
+		Raw:      raw,
+		err:      err,
+	}
+
+	return e
+}
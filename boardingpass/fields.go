@@ -0,0 +1,117 @@
+package boardingpass
+
+import (
+	"github.com/sfomuseum/go-bcbp"
+)
+
+/*
+
+Conditional Fields (Variable Length)
+These fields follow the 60 mandatory characters of a leg. Their combined length is
+declared by the (hex) "field size of variable size field" in the mandatory section.
+
+Unique items (first leg only)
+
+Field No.	Field Name	Length (Chars)	Notes
+8	Beginning of Version Number	1	">"
+9	Version Number	1	"1" - "8"
+10	Field Size of Following Structured Message (Unique)	2	Hexadecimal
+15	Passenger Description	1
+12	Source of Check-In	1
+14	Source of Boarding Pass Issuance	1
+22	Date of Issue of Boarding Pass (Julian Date)	4	Format: YDDD
+16	Document Type	1	"B" = Boarding pass, "I" = Itinerary receipt
+21	Airline Designator of Boarding Pass Issuer	3
+23	Baggage Tag License Plate Number(s)	13
+31	1st Non-Consecutive Baggage Tag License Plate Number	13
+32	2nd Non-Consecutive Baggage Tag License Plate Number	13
+
+Repeated items (every leg)
+
+Field No.	Field Name	Length (Chars)	Notes
+17	Field Size of Following Structured Message (Repeated)	2	Hexadecimal
+142	Airline Numeric Code	3
+143	Document Form/Serial Number	10
+18	Selectee Indicator	1
+108	International Documentation Verification	1
+19	Marketing Carrier Designator	3
+20	Frequent Flyer Airline Designator	3
+236	Frequent Flyer Number	16
+89	ID/AD Indicator	1
+118	Free Baggage Allowance	3
+254	Fast Track	1	"Y" or "N"
+4	For Individual Airline Use	Variable
+
+*/
+
+const BEGINNING_OF_VERSION_NUMBER_MARKER string = ">"
+
+const BEGINNING_OF_VERSION_NUMBER int = 1
+const VERSION_NUMBER int = 1
+const UNIQUE_FIELD_SIZE int = 2
+
+const PASSENGER_DESCRIPTION int = 1
+const SOURCE_OF_CHECK_IN int = 1
+const SOURCE_OF_BOARDING_PASS_ISSUANCE int = 1
+const DATE_OF_ISSUE int = 4
+const DOCUMENT_TYPE int = 1
+const AIRLINE_DESIGNATOR_OF_ISSUER int = 3
+const BAGGAGE_TAG_LICENSE_PLATE int = 13
+const FIRST_NON_CONSECUTIVE_BAGGAGE_TAG int = 13
+const SECOND_NON_CONSECUTIVE_BAGGAGE_TAG int = 13
+
+const REPEATED_FIELD_SIZE int = 2
+
+const AIRLINE_NUMERIC_CODE int = 3
+const DOCUMENT_SERIAL_NUMBER int = 10
+const SELECTEE_INDICATOR int = 1
+const INTERNATIONAL_DOCUMENTATION_VERIFICATION int = 1
+const MARKETING_CARRIER_DESIGNATOR int = 3
+const FREQUENT_FLYER_AIRLINE_DESIGNATOR int = 3
+const FREQUENT_FLYER_NUMBER int = 16
+const ID_AD_INDICATOR int = 1
+const FREE_BAGGAGE_ALLOWANCE int = 3
+const FAST_TRACK int = 1
+
+// REPEATED_MANDATORY is the number of mandatory characters in the second and subsequent legs of a BCBP
+// string. These legs omit the format code, number of legs, passenger name and electronic ticket indicator.
+const REPEATED_MANDATORY int = bcbp.OPTIONAL_DATA_OFFSET - bcbp.OPERATING_CARRIER_PNR_OFFSET
+
+// field describes the name, offset and length of a mandatory field.
+type field struct {
+	Name   string
+	Offset int
+	Length int
+}
+
+// mandatoryFields are the mandatory fields of a leg, named after the JSON properties of `bcbp.Leg`.
+var mandatoryFields = []*field{
+	{"format_code", bcbp.FORMAT_CODE_OFFSET, bcbp.FORMAT_CODE},
+	{"number_of_legs", bcbp.NUMBER_OF_LEGS_OFFSET, bcbp.NUMBER_OF_LEGS},
+	{"passenger_name", bcbp.PASSENGER_NAME_OFFSET, bcbp.PASSENGER_NAME},
+	{"electronic_ticket_indicator", bcbp.ELECTRONIC_TICKET_INDICATOR_OFFSET, bcbp.ELECTRONIC_TICKET_INDICATOR},
+	{"operating_carrier_pnr", bcbp.OPERATING_CARRIER_PNR_OFFSET, bcbp.OPERATING_CARRIER_PNR},
+	{"from_airport", bcbp.DEPARTURE_AIRPORT_OFFSET, bcbp.DEPARTURE_AIRPORT},
+	{"to_airport", bcbp.ARRIVAL_AIRPORT_OFFSET, bcbp.ARRIVAL_AIRPORT},
+	{"operating_carrier_designator", bcbp.OPERATING_CARRIER_DESIGNATOR_OFFSET, bcbp.OPERATING_CARRIER_DESIGNATOR},
+	{"flight_number", bcbp.FLIGHT_NUMBER_OFFSET, bcbp.FLIGHT_NUMBER},
+	{"date_of_flight", bcbp.FLIGHT_DATE_OFFSET, bcbp.FLIGHT_DATE},
+	{"compartment_code", bcbp.COMPARTMENT_CODE_OFFSET, bcbp.COMPARTMENT_CODE},
+	{"seat_number", bcbp.SEAT_NUMBER_OFFSET, bcbp.SEAT_NUMBER},
+	{"checkin_sequence_number", bcbp.CHECK_IN_SEQUENCE_NUMBER_OFFSET, bcbp.CHECK_IN_SEQUENCE_NUMBER},
+	{"passenger_status", bcbp.PASSENGER_STATUS_OFFSET, bcbp.PASSENGER_STATUS},
+	{"optional_data_size", bcbp.OPTIONAL_DATA_SIZE_OFFSET, bcbp.OPTIONAL_DATA_SIZE},
+}
+
+// mandatoryField returns the mandatory field containing 'offset'.
+func mandatoryField(offset int) *field {
+
+	for _, f := range mandatoryFields {
+
+		if offset >= f.Offset && offset < f.Offset+f.Length {
+			return f
+		}
+	}
+
+	return nil
+}
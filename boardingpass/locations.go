@@ -0,0 +1,96 @@
+package boardingpass
+
+import (
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// FieldLocation describes where a field, or conditional item, was found in a BCBP string.
+type FieldLocation struct {
+	// The name of the field, or conditional item. These match the JSON property names of `bcbp.Leg`,
+	// `Conditional` and `Security`.
+	Field string `json:"field"`
+	// The offset of the first character of the field in the BCBP string.
+	Offset int `json:"offset"`
+	// The number of characters the field occupies in the BCBP string.
+	Length int `json:"length"`
+	// The raw, untrimmed, characters of the field.
+	Raw string `json:"raw"`
+	// The decoded value of the field.
+	Value any `json:"value"`
+}
+
+// locate records the location of a field if 'd' has been configured to produce field maps.
+func (d *decoder) locate(field string, offset int, raw string, value any) {
+
+	if !d.field_map {
+		return
+	}
+
+	l := &FieldLocation{
+		Field:  field,
+		Offset: offset,
+		Length: len(raw),
+		Raw:    raw,
+		Value:  value,
+	}
+
+	d.locations = append(d.locations, l)
+}
+
+// locateMandatory records the locations of the mandatory fields of 'l', parsed from 's', in the BCBP string 'raw'.
+// Fields copied from the first leg in to subsequent legs are not recorded.
+func (d *decoder) locateMandatory(raw string, s *legSpan, l *bcbp.Leg) {
+
+	for _, f := range mandatoryFields {
+
+		if f.Offset < s.prefix {
+			continue
+		}
+
+		start := min(s.position(f.Offset), len(raw))
+		end := min(s.position(f.Offset+f.Length), len(raw))
+
+		// Use the characters from the BCBP string rather than 's.raw' which may have been padded
+
+		d.locate(f.Name, start, raw[start:end], mandatoryValue(l, f.Name))
+	}
+}
+
+// mandatoryValue returns the value of the mandatory field 'name' in 'l'.
+func mandatoryValue(l *bcbp.Leg, name string) string {
+
+	switch name {
+	case "format_code":
+		return l.FormatCode
+	case "number_of_legs":
+		return l.NumberOfLegs
+	case "passenger_name":
+		return l.PassengerName
+	case "electronic_ticket_indicator":
+		return l.ElectronicTicketIndicator
+	case "operating_carrier_pnr":
+		return l.OperatingCarrierPNR
+	case "from_airport":
+		return l.FromAirport
+	case "to_airport":
+		return l.ToAirport
+	case "operating_carrier_designator":
+		return l.OperatingCarrierDesignator
+	case "flight_number":
+		return l.FlightNumber
+	case "date_of_flight":
+		return l.DateOfFlight
+	case "compartment_code":
+		return l.CompartmentCode
+	case "seat_number":
+		return l.SeatNumber
+	case "checkin_sequence_number":
+		return l.CheckInSequenceNumber
+	case "passenger_status":
+		return l.PassengerStatus
+	case "optional_data_size":
+		return l.OptionalDataSize
+	default:
+		return ""
+	}
+}
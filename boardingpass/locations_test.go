@@ -0,0 +1,73 @@
+package boardingpass
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFieldMap parses BCBP strings with field maps enabled and ensures that the offset and length of every field
+// locates its raw characters in the original BCBP string.
+func TestFieldMap(t *testing.T) {
+
+	leg_1 := "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z"
+	leg_2 := "DEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ"
+
+	tests := []struct {
+		name    string
+		raw     string
+		lenient bool
+		// A field whose offset is checked, the index of its leg and its expected offset.
+		field  string
+		leg    int
+		offset int
+	}{
+		{"single_leg", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100", false, "flight_number", 0, 39},
+		{"concatenated", leg_1 + leg_2, false, "operating_carrier_pnr", 1, len(leg_1)},
+		{"conditional", leg_1 + leg_2, false, "frequent_flyer_number", 1, strings.LastIndex(leg_2, "1234567890123") + len(leg_1)},
+		{"group_separator", leg_1 + "\x1dM2DESMARAIS/LUC       E" + leg_2, false, "from_airport", 1, len(leg_1) + 1 + 23 + 7},
+		{"security_data", leg_1 + leg_2 + "^108ABCDEFGH", false, "security_data", 1, len(leg_1) + len(leg_2) + 4},
+		{"truncated_passenger_name", "M1DESMARAIS/LUC EABC123 YULFRAAC 0834 226F001A0025 100", true, "operating_carrier_pnr", 0, 17},
+	}
+
+	for _, tt := range tests {
+
+		b, err := UnmarshalWithOptions(tt.raw, &UnmarshalOptions{Lenient: tt.lenient, FieldMap: true})
+
+		if err != nil {
+			t.Errorf("%s: failed to parse, %v", tt.name, err)
+			continue
+		}
+
+		found := false
+
+		for idx, fm := range fieldMaps(b) {
+
+			for _, loc := range fm {
+
+				if loc.Offset < 0 || loc.Offset+loc.Length > len(tt.raw) {
+					t.Errorf("%s: %s (%d, %d) is out of range", tt.name, loc.Field, loc.Offset, loc.Length)
+					continue
+				}
+
+				raw := tt.raw[loc.Offset : loc.Offset+loc.Length]
+
+				if raw != loc.Raw {
+					t.Errorf("%s: expected %s at offset %d to be '%s', got '%s'", tt.name, loc.Field, loc.Offset, loc.Raw, raw)
+				}
+
+				if idx == tt.leg && loc.Field == tt.field {
+
+					found = true
+
+					if loc.Offset != tt.offset {
+						t.Errorf("%s: expected %s at offset %d, got %d", tt.name, loc.Field, tt.offset, loc.Offset)
+					}
+				}
+			}
+		}
+
+		if !found {
+			t.Errorf("%s: expected a location for %s in leg %d", tt.name, tt.field, tt.leg)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package boardingpass
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// RawLeg is the exact characters of a leg parsed in lossless mode.
+type RawLeg struct {
+	// Any characters preceding the leg, for example an ASCII group separator character.
+	Leading string `json:"leading,omitempty"`
+	// The raw, untrimmed, characters of each mandatory field keyed by field name. Fields copied from the first leg
+	// in to subsequent legs are not included.
+	Fields map[string]string `json:"fields"`
+	// The raw characters of the variable size field, excluding any security data section.
+	VariableSizeField string `json:"variable_size_field"`
+	// Any characters between the variable size field of the last leg and the security data section.
+	Unused string `json:"unused,omitempty"`
+	// A copy of the conditional section as it was decoded, used to determine whether it has since changed.
+	conditional *Conditional
+}
+
+// newRawLeg returns the `RawLeg` for 's' in the BCBP string 'raw', where 'end' is the offset of the character
+// following the previous leg, 'base' is the offset of the variable size field and 'variable' is its value. It also
+// returns the offset of the character following the leg.
+func newRawLeg(raw string, s *legSpan, end int, base int, variable string) (*RawLeg, int) {
+
+	start := min(s.offset, len(raw))
+
+	// Truncated legs end at the next group separator, if present, rather than their padded length
+
+	limit := len(raw)
+	idx := strings.IndexRune(raw[start:], bcbp.GROUP_SEPARATOR)
+
+	if idx != -1 {
+		limit = start + idx
+	}
+
+	r := &RawLeg{
+		Leading:           raw[min(end, start):start],
+		Fields:            make(map[string]string),
+		VariableSizeField: raw[min(base, limit):min(base+len(variable), limit)],
+	}
+
+	for _, f := range mandatoryFields {
+
+		if f.Offset < s.prefix {
+			continue
+		}
+
+		f_start := min(s.position(f.Offset), limit)
+		f_end := min(s.position(f.Offset+f.Length), limit)
+
+		r.Fields[f.Name] = raw[f_start:f_end]
+	}
+
+	return r, min(base+len(variable), limit)
+}
+
+// verifyLossless ensures that 'b', parsed in lossless mode, re-encodes to 'raw'.
+func verifyLossless(b *BCBP, raw string) error {
+
+	enc, err := Marshal(b)
+
+	if err != nil {
+		return newError(fmt.Errorf("Failed to re-encode BCBP string, %w", err), ERROR_LOSSLESS_MISMATCH, raw)
+	}
+
+	if enc != raw {
+		return newError(fmt.Errorf("Re-encoded BCBP string '%s' does not match original", enc), ERROR_LOSSLESS_MISMATCH, raw)
+	}
+
+	return nil
+}
+
+// encodeLossless returns the leg 'l', parsed in lossless mode, as a string. Mandatory fields whose values have not
+// changed are encoded using their original characters, otherwise the padded values in 'enc' are used. If
+// 'unchanged' is true the variable size field is assumed to be the original characters and the original field
+// size is retained. 'count' is the number of legs being encoded and 'lossless' indicates whether every leg was
+// parsed in lossless mode.
+func encodeLossless(l *Leg, enc *bcbp.Leg, count int, lossless bool, unchanged bool) string {
+
+	var sb strings.Builder
+
+	sb.WriteString(l.Raw.Leading)
+
+	for _, f := range mandatoryFields {
+
+		raw, ok := l.Raw.Fields[f.Name]
+
+		if !ok {
+			continue
+		}
+
+		keep := false
+
+		switch f.Name {
+		case "number_of_legs":
+			// An incorrect number of legs is only retained if no legs have been added
+			keep = cleanMandatory(f, raw) == l.NumberOfLegs && (lossless || raw == strconv.Itoa(count))
+		case "optional_data_size":
+			keep = unchanged
+		default:
+			keep = cleanMandatory(f, raw) == mandatoryValue(l.Leg, f.Name)
+		}
+
+		if keep {
+			sb.WriteString(raw)
+		} else {
+			sb.WriteString(mandatoryValue(enc, f.Name))
+		}
+	}
+
+	sb.WriteString(enc.OptionalData)
+	return sb.String()
+}
+
+// cleanMandatory returns the value `bcbp.ParseLeg` derives from 'raw', the characters of the mandatory field 'f'.
+func cleanMandatory(f *field, raw string) string {
+
+	// Truncated mandatory sections are padded with spaces before they are parsed
+
+	raw = rightPad(raw, f.Length)
+
+	switch f.Name {
+	case "passenger_name", "operating_carrier_pnr", "from_airport", "to_airport", "operating_carrier_designator", "flight_number":
+		return strings.TrimSpace(raw)
+	case "seat_number", "checkin_sequence_number":
+		return strings.TrimLeft(raw, "0")
+	default:
+		return raw
+	}
+}
+
+// copyConditional returns a copy of the conditional section decoded from 'variable' by 'd', without recording any
+// warnings or field locations, where 'first' indicates whether 'variable' belongs to the first leg.
+func (d *decoder) copyConditional(variable string, first bool) *Conditional {
+
+	// Subsequent legs are decoded using the version declared by the first leg, as they were by 'd'
+
+	probe := &decoder{
+		lenient: d.lenient,
+		version: d.version,
+	}
+
+	c, err := probe.parseConditional(variable, 0, first)
+
+	if err != nil {
+		return nil
+	}
+
+	return c
+}
+
+// variableUnchanged returns true if the conditional section (or optional data, if the conditional section could
+// not be decoded) of 'l', parsed in lossless mode, still matches the original variable size field.
+func variableUnchanged(l *Leg, first bool) bool {
+
+	raw := l.Raw.VariableSizeField
+
+	if l.Conditional == nil {
+		return strings.HasPrefix(l.OptionalData, raw)
+	}
+
+	// Legs parsed by `UnmarshalWithOptions` retain a copy of the conditional section as it was decoded, which may
+	// depend on the legs preceding it, so it is not decoded again
+
+	if l.Raw.conditional != nil {
+		return reflect.DeepEqual(l.Raw.conditional, l.Conditional)
+	}
+
+	// The leg may have been parsed in either mode and lenient mode may repair problems
+
+	for _, lenient := range []bool{false, true} {
+
+		d := &decoder{
+			lenient: lenient,
+		}
+
+		c, err := d.parseConditional(raw, 0, first)
+
+		if err == nil && reflect.DeepEqual(c, l.Conditional) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// securityUnchanged returns true if the type and data of 's' still match its raw characters.
+func securityUnchanged(s *Security) bool {
+
+	if s.Raw == "" {
+		return false
+	}
+
+	d := &decoder{
+		lenient: true,
+	}
+
+	orig, err := d.parseSecurity(s.Raw, 0)
+
+	if err != nil {
+		return false
+	}
+
+	return orig.Type == s.Type && orig.Data == s.Data
+}
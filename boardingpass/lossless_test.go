@@ -0,0 +1,78 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestLossless parses BCBP strings which differ from their canonical form in lossless mode and ensures that they
+// are re-encoded byte for byte.
+func TestLossless(t *testing.T) {
+
+	multi_leg := "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>5181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ^108ABCDEFGH"
+
+	tests := []struct {
+		name    string
+		raw     string
+		lenient bool
+	}{
+		{"zero_padded_flight_number", "M1DESMARAIS/LUC       EABC123 YULFRAAC 00834226F001A0025 100", false},
+		{"space_padded_flight_number", "M1DESMARAIS/LUC       EABC123 YULFRAAC 834  226F001A0025 100", false},
+		{"unpadded_seat", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F1A  0025 100", false},
+		{"unpadded_checkin_sequence_number", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A25   100", false},
+		{"lower_case", "M1desmarais/luc       EABC123 YULFRAAC 0834 226F001A0025 100", false},
+		{"multi_leg", multi_leg, false},
+		{"multi_leg_lenient", multi_leg, true},
+		{"group_separator", "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100\x1dM2DESMARAIS/LUC       EDEF456 FRAGVALH 3664 227C012C0002 100", true},
+		{"missing_version_number", "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14C>181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ", true},
+		{"field_size_mismatch", "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>5181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNW", true},
+		{"stray_characters", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100Z^108ABCDEFGH", false},
+		{"trailing_data", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100\r\n", false},
+	}
+
+	for _, tt := range tests {
+
+		b, err := UnmarshalWithOptions(tt.raw, &UnmarshalOptions{Lenient: tt.lenient, Lossless: true})
+
+		if err != nil {
+			t.Errorf("%s: failed to parse, %v", tt.name, err)
+			continue
+		}
+
+		enc, err := Marshal(b)
+
+		if err != nil {
+			t.Errorf("%s: failed to marshal, %v", tt.name, err)
+			continue
+		}
+
+		if enc != tt.raw {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.raw, enc)
+		}
+	}
+}
+
+// TestLosslessModified ensures that a conditional item modified after a multi-leg BCBP string was parsed in lossless
+// mode is encoded from its value while every other leg retains its original characters.
+func TestLosslessModified(t *testing.T) {
+
+	raw := "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>5181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ^108ABCDEFGH"
+	expected := "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>5181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    3PCNWQ^108ABCDEFGH"
+
+	b, err := UnmarshalWithOptions(raw, &UnmarshalOptions{Lossless: true})
+
+	if err != nil {
+		t.Fatalf("Failed to parse, %v", err)
+	}
+
+	b.Legs[1].Conditional.FreeBaggageAllowance.Value = "3PC"
+
+	enc, err := Marshal(b)
+
+	if err != nil {
+		t.Fatalf("Failed to marshal, %v", err)
+	}
+
+	if enc != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, enc)
+	}
+}
@@ -0,0 +1,522 @@
+package boardingpass
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// MAX_FIELD_SIZE is the largest length that can be encoded in a two character hexadecimal field size.
+const MAX_FIELD_SIZE int = 255
+
+var re_flight_number = regexp.MustCompile(`^([0-9]{1,4})([A-Z ]?)$`)
+var re_seat_number = regexp.MustCompile(`^([0-9]{1,3})([A-Z])$`)
+var re_checkin_sequence_number = regexp.MustCompile(`^([0-9]{1,4})([A-Z ]?)$`)
+var re_date_of_flight = regexp.MustCompile(`^[0-9]{1,3}$`)
+
+// MarshalOptions defines options for encoding BCBP strings.
+type MarshalOptions struct {
+	// GroupSeparator enables encoding each leg in full and separating legs with the ASCII group separator
+	// character, as `bcbp.Marshal` does, rather than concatenating them as described by Resolution 792.
+	GroupSeparator bool
+}
+
+// Marshal encodes 'b' as a BCBP string. See `MarshalWithOptions` for details.
+func Marshal(b *BCBP) (string, error) {
+	opts := &MarshalOptions{}
+	return MarshalWithOptions(b, opts)
+}
+
+// MarshalWithOptions encodes 'b' as a BCBP string using 'opts'. The mandatory fields of each leg are padded to
+// their fixed widths using `bcbp.Leg.String` and an error is returned if a value is too long for its field. The
+// number of legs, which may not exceed MAX_LEGS, and the field size of each leg's variable size field are computed
+// rather than copied from 'b'.
+// The format code, passenger name and electronic ticket indicator are always taken from the first leg.
+//
+// If a leg has a decoded conditional section its variable size field is encoded from it, using
+// `MarshalConditional`, otherwise the leg's optional data is copied verbatim. If 'b' has security data it is
+// encoded using `MarshalSecurity` and appended to the last leg.
+//
+// Legs parsed in lossless mode are encoded using their original characters, including any separators, padding
+// and field sizes, for every field whose value has not changed. Along with the characters following the last leg
+// this reproduces the original BCBP string byte for byte if nothing has been modified.
+func MarshalWithOptions(b *BCBP, opts *MarshalOptions) (string, error) {
+
+	if len(b.Legs) == 0 {
+		return "", newError(fmt.Errorf("BCBP must contain at least one leg"), ERROR_EMPTY_INPUT, "")
+	}
+
+	e := &encoder{}
+
+	legs := make([]*bcbp.Leg, len(b.Legs))
+
+	// Whether or not each leg's variable size field is unchanged since it was parsed in lossless mode
+	unchanged := make([]bool, len(b.Legs))
+	lossless := true
+
+	for idx, l := range b.Legs {
+
+		if l == nil || l.Leg == nil || l.Raw == nil {
+			lossless = false
+			continue
+		}
+
+		unchanged[idx] = variableUnchanged(l, idx == 0)
+	}
+
+	// Resolution 792 permits at most MAX_LEGS legs. The original number of legs is retained if every leg was parsed
+	// in lossless mode so that the original BCBP string can be reproduced
+
+	if len(b.Legs) > MAX_LEGS && !lossless {
+		err := fmt.Errorf("BCBP can not contain more than %d legs, got %d", MAX_LEGS, len(b.Legs))
+		return "", newError(err, ERROR_FIELD_OVERFLOW, strconv.Itoa(len(b.Legs)))
+	}
+
+	last := b.Legs[len(b.Legs)-1]
+	security := ""
+
+	switch {
+	case b.Security == nil:
+		// pass
+	case last != nil && last.Raw != nil && securityUnchanged(b.Security):
+		security = b.Security.Raw
+	default:
+
+		e.leg = len(b.Legs) - 1
+
+		enc, err := e.encodeSecurity(b.Security)
+
+		if err != nil {
+			return "", err
+		}
+
+		security = enc
+	}
+
+	for idx, l := range b.Legs {
+
+		e.leg = idx
+
+		if l == nil || l.Leg == nil {
+			return "", e.fail(fmt.Errorf("Leg %d has no fields", idx), ERROR_FIELD_INVALID, "", "")
+		}
+
+		variable := l.OptionalData
+
+		if unchanged[idx] {
+
+			variable = l.Raw.VariableSizeField
+
+		} else if l.Conditional != nil {
+
+			enc, err := e.encodeConditional(l.Conditional, idx == 0)
+
+			if err != nil {
+				return "", err
+			}
+
+			variable = enc
+
+		} else if idx == len(b.Legs)-1 && b.Security != nil {
+
+			// The optional data of the last leg, as returned by bcbp.ParseLeg, may include the security data section
+			variable = strings.TrimSuffix(variable, b.Security.Raw)
+		}
+
+		first := l.Leg
+
+		if idx > 0 {
+			first = b.Legs[0].Leg
+		}
+
+		// The original field size of an unchanged variable size field is retained, even if it does not describe
+		// the field, so its length is not checked
+
+		checked := variable
+
+		if unchanged[idx] {
+			checked = ""
+		}
+
+		enc, err := e.encodeLeg(l.Leg, first, len(b.Legs), checked)
+
+		if err != nil {
+			return "", err
+		}
+
+		enc.OptionalData = variable
+		legs[idx] = enc
+	}
+
+	// Legs parsed in lossless mode retain any characters between the last leg and the security data section
+
+	if last.Raw != nil && security != "" {
+		security = last.Raw.Unused + security
+	}
+
+	legs[len(legs)-1].OptionalData += security
+
+	var sb strings.Builder
+
+	for idx, l := range legs {
+
+		// Legs parsed in lossless mode retain their original separators and padding
+
+		if b.Legs[idx].Raw != nil {
+			sb.WriteString(encodeLossless(b.Legs[idx], l, len(legs), lossless, unchanged[idx]))
+			continue
+		}
+
+		enc := l.String()
+
+		// Subsequent legs omit the items which are only encoded in the first leg, unless they are separated by
+		// the ASCII group separator character
+
+		if idx > 0 {
+
+			if opts.GroupSeparator {
+				enc = string(bcbp.GROUP_SEPARATOR) + enc
+			} else {
+				enc = enc[bcbp.OPERATING_CARRIER_PNR_OFFSET:]
+			}
+		}
+
+		sb.WriteString(enc)
+	}
+
+	sb.WriteString(b.Trailing)
+	return sb.String(), nil
+}
+
+// MarshalConditional encodes 'c' as the variable size field of a leg, computing the field size of each section.
+// If 'first' is true 'c' is assumed to belong to the first leg of a BCBP string and its version number and unique
+// conditional items are encoded. Items which are nil are encoded as spaces, unless they follow the last item of a
+// section in which case they are omitted. If the raw characters of an item decode to its value they are used
+// as-is, preserving any spacing, otherwise the value is encoded.
+func MarshalConditional(c *Conditional, first bool) (string, error) {
+	e := &encoder{}
+	return e.encodeConditional(c, first)
+}
+
+// MarshalSecurity encodes 's' as a security data section, computing the length of security data. If 's' has no
+// security data its signature is encoded using its encoding (hexadecimal by default).
+func MarshalSecurity(s *Security) (string, error) {
+	e := &encoder{}
+	return e.encodeSecurity(s)
+}
+
+// encoder keeps track of the leg currently being encoded and the first error encountered while encoding
+// conditional items.
+type encoder struct {
+	leg int
+	err error
+}
+
+// encodedItem is an encoded conditional item and its fixed width.
+type encodedItem struct {
+	// The encoded item, or an empty string if the item is absent.
+	raw    string
+	length int
+}
+
+// fail returns a new `Error` for the leg currently being encoded.
+func (e *encoder) fail(err error, code string, field string, raw string) *Error {
+
+	bp_err := &Error{
+		Code:     code,
+		Field:    field,
+		Offset:   -1,
+		LegIndex: e.leg,
+		Raw:      raw,
+		err:      err,
+	}
+
+	return bp_err
+}
+
+// encodeLeg returns a copy of 'l' whose fields have been padded to their fixed widths, with the items only encoded
+// in the first leg copied from 'first', the number of legs set to 'count' and the variable size field set to
+// 'variable'.
+func (e *encoder) encodeLeg(l *bcbp.Leg, first *bcbp.Leg, count int, variable string) (*bcbp.Leg, error) {
+
+	if len(variable) > MAX_FIELD_SIZE {
+		err := fmt.Errorf("Variable size field can not be longer than %d characters, got %d", MAX_FIELD_SIZE, len(variable))
+		return nil, e.fail(err, ERROR_FIELD_OVERFLOW, "optional_data", variable)
+	}
+
+	format_code := first.FormatCode
+
+	if format_code == "" {
+		format_code = "M"
+	}
+
+	enc := &bcbp.Leg{
+		NumberOfLegs:     strconv.Itoa(count),
+		OptionalDataSize: fmt.Sprintf("%02X", len(variable)),
+		OptionalData:     variable,
+	}
+
+	fields := []struct {
+		name   string
+		value  string
+		width  int
+		target *string
+	}{
+		{"format_code", format_code, bcbp.FORMAT_CODE, &enc.FormatCode},
+		{"passenger_name", first.PassengerName, bcbp.PASSENGER_NAME, &enc.PassengerName},
+		{"electronic_ticket_indicator", first.ElectronicTicketIndicator, bcbp.ELECTRONIC_TICKET_INDICATOR, &enc.ElectronicTicketIndicator},
+		{"operating_carrier_pnr", l.OperatingCarrierPNR, bcbp.OPERATING_CARRIER_PNR, &enc.OperatingCarrierPNR},
+		{"from_airport", l.FromAirport, bcbp.DEPARTURE_AIRPORT, &enc.FromAirport},
+		{"to_airport", l.ToAirport, bcbp.ARRIVAL_AIRPORT, &enc.ToAirport},
+		{"operating_carrier_designator", l.OperatingCarrierDesignator, bcbp.OPERATING_CARRIER_DESIGNATOR, &enc.OperatingCarrierDesignator},
+		{"flight_number", padNumber(l.FlightNumber, re_flight_number, 4, " "), bcbp.FLIGHT_NUMBER, &enc.FlightNumber},
+		{"date_of_flight", padDateOfFlight(l.DateOfFlight), bcbp.FLIGHT_DATE, &enc.DateOfFlight},
+		{"compartment_code", l.CompartmentCode, bcbp.COMPARTMENT_CODE, &enc.CompartmentCode},
+		{"seat_number", padNumber(l.SeatNumber, re_seat_number, 3, ""), bcbp.SEAT_NUMBER, &enc.SeatNumber},
+		{"checkin_sequence_number", padNumber(l.CheckInSequenceNumber, re_checkin_sequence_number, 4, " "), bcbp.CHECK_IN_SEQUENCE_NUMBER, &enc.CheckInSequenceNumber},
+		{"passenger_status", l.PassengerStatus, bcbp.PASSENGER_STATUS, &enc.PassengerStatus},
+	}
+
+	for _, f := range fields {
+
+		if len(f.value) > f.width {
+			err := fmt.Errorf("%s can not be longer than %d characters, got '%s'", f.name, f.width, f.value)
+			return nil, e.fail(err, ERROR_FIELD_OVERFLOW, f.name, f.value)
+		}
+
+		*f.target = rightPad(f.value, f.width)
+	}
+
+	return enc, nil
+}
+
+// padNumber zero-pads the digits of 'raw', a number followed by an optional letter which is matched by 're', to
+// 'digits' characters. If there is no letter 'suffix' is appended. Values which are not matched by 're' are
+// returned unchanged.
+func padNumber(raw string, re *regexp.Regexp, digits int, suffix string) string {
+
+	m := re.FindStringSubmatch(strings.TrimSpace(raw))
+
+	if m == nil {
+		return raw
+	}
+
+	if m[2] == "" {
+		m[2] = suffix
+	}
+
+	return strings.Repeat("0", max(digits-len(m[1]), 0)) + m[1] + m[2]
+}
+
+// padDateOfFlight zero-pads 'raw', a day of year, to three digits.
+func padDateOfFlight(raw string) string {
+
+	raw = strings.TrimSpace(raw)
+
+	if !re_date_of_flight.MatchString(raw) {
+		return raw
+	}
+
+	return strings.Repeat("0", bcbp.FLIGHT_DATE-len(raw)) + raw
+}
+
+// rightPad pads 'raw' with spaces to 'length' characters.
+func rightPad(raw string, length int) string {
+	return raw + strings.Repeat(" ", max(length-len(raw), 0))
+}
+
+func (e *encoder) encodeConditional(c *Conditional, first bool) (string, error) {
+
+	var sb strings.Builder
+
+	unique := joinItems([]*encodedItem{
+		encodeItem(e, c.PassengerDescription, PASSENGER_DESCRIPTION, "passenger_description", stringItem, encodeString),
+		encodeItem(e, c.SourceOfCheckIn, SOURCE_OF_CHECK_IN, "source_of_checkin", stringItem, encodeString),
+		encodeItem(e, c.SourceOfBoardingPassIssuance, SOURCE_OF_BOARDING_PASS_ISSUANCE, "source_of_boarding_pass_issuance", stringItem, encodeString),
+		encodeItem(e, c.DateOfIssue, DATE_OF_ISSUE, "date_of_issue", issueDateItem, encodeIssueDate),
+		encodeItem(e, c.DocumentType, DOCUMENT_TYPE, "document_type", stringItem, encodeString),
+		encodeItem(e, c.AirlineDesignatorOfIssuer, AIRLINE_DESIGNATOR_OF_ISSUER, "airline_designator_of_issuer", stringItem, encodeString),
+		encodeItem(e, c.BaggageTagLicensePlate, BAGGAGE_TAG_LICENSE_PLATE, "baggage_tag_license_plate", baggageTagItem, encodeBaggageTag),
+		encodeItem(e, c.FirstNonConsecutiveBaggageTag, FIRST_NON_CONSECUTIVE_BAGGAGE_TAG, "first_non_consecutive_baggage_tag", baggageTagItem, encodeBaggageTag),
+		encodeItem(e, c.SecondNonConsecutiveBaggageTag, SECOND_NON_CONSECUTIVE_BAGGAGE_TAG, "second_non_consecutive_baggage_tag", baggageTagItem, encodeBaggageTag),
+	})
+
+	repeated := joinItems([]*encodedItem{
+		encodeItem(e, c.AirlineNumericCode, AIRLINE_NUMERIC_CODE, "airline_numeric_code", stringItem, encodeString),
+		encodeItem(e, c.DocumentSerialNumber, DOCUMENT_SERIAL_NUMBER, "document_serial_number", stringItem, encodeString),
+		encodeItem(e, c.SelecteeIndicator, SELECTEE_INDICATOR, "selectee_indicator", stringItem, encodeString),
+		encodeItem(e, c.InternationalDocumentationVerification, INTERNATIONAL_DOCUMENTATION_VERIFICATION, "international_documentation_verification", stringItem, encodeString),
+		encodeItem(e, c.MarketingCarrierDesignator, MARKETING_CARRIER_DESIGNATOR, "marketing_carrier_designator", stringItem, encodeString),
+		encodeItem(e, c.FrequentFlyerAirlineDesignator, FREQUENT_FLYER_AIRLINE_DESIGNATOR, "frequent_flyer_airline_designator", stringItem, encodeString),
+		encodeItem(e, c.FrequentFlyerNumber, FREQUENT_FLYER_NUMBER, "frequent_flyer_number", stringItem, encodeString),
+		encodeItem(e, c.IDADIndicator, ID_AD_INDICATOR, "id_ad_indicator", stringItem, encodeString),
+		encodeItem(e, c.FreeBaggageAllowance, FREE_BAGGAGE_ALLOWANCE, "free_baggage_allowance", stringItem, encodeString),
+		encodeItem(e, c.FastTrack, FAST_TRACK, "fast_track", fastTrackItem, encodeFastTrack),
+	})
+
+	version := encodeItem(e, c.VersionNumber, VERSION_NUMBER, "version_number", intItem, strconv.Itoa)
+
+	if e.err != nil {
+		return "", e.err
+	}
+
+	if first {
+
+		// Without a version number the variable size field of the first leg is read as airline data
+
+		if version.raw == "" {
+
+			if unique != "" || repeated != "" {
+				err := fmt.Errorf("A version number is required to encode conditional items in the first leg")
+				return "", e.fail(err, ERROR_FIELD_INVALID, "version_number", "")
+			}
+
+			return c.AirlineData, nil
+		}
+
+		sb.WriteString(BEGINNING_OF_VERSION_NUMBER_MARKER)
+		sb.WriteString(version.raw)
+		sb.WriteString(fmt.Sprintf("%02X", len(unique)))
+		sb.WriteString(unique)
+	}
+
+	// Subsequent legs, and the first leg once its unique items have been read, always start with the field size
+	// of the repeated items if there is any data
+
+	if repeated != "" || c.AirlineData != "" {
+		sb.WriteString(fmt.Sprintf("%02X", len(repeated)))
+		sb.WriteString(repeated)
+	}
+
+	sb.WriteString(c.AirlineData)
+	return sb.String(), nil
+}
+
+func (e *encoder) encodeSecurity(s *Security) (string, error) {
+
+	if len(s.Type) != TYPE_OF_SECURITY_DATA {
+		err := fmt.Errorf("Type of security data must be %d character, got '%s'", TYPE_OF_SECURITY_DATA, s.Type)
+		return "", e.fail(err, ERROR_FIELD_INVALID, "type_of_security_data", s.Type)
+	}
+
+	data := s.Data
+
+	if data == "" && len(s.Signature) > 0 {
+
+		switch s.Encoding {
+		case "base64":
+			data = base64.StdEncoding.EncodeToString(s.Signature)
+		default:
+			data = strings.ToUpper(hex.EncodeToString(s.Signature))
+		}
+	}
+
+	if len(data) > MAX_FIELD_SIZE {
+		err := fmt.Errorf("Security data can not be longer than %d characters, got %d", MAX_FIELD_SIZE, len(data))
+		return "", e.fail(err, ERROR_FIELD_OVERFLOW, "security_data", data)
+	}
+
+	enc := BEGINNING_OF_SECURITY_DATA_MARKER + s.Type + fmt.Sprintf("%02X", len(data)) + data
+	return enc, nil
+}
+
+// encodeItem encodes 'i' as a 'length' character item using 'encode_func'. If the raw characters of 'i' decode,
+// using 'decode_func', to the value of 'i', or can not be decoded at all, they are used instead. If 'i' is nil
+// an empty item is returned. Problems are recorded as the encoder's error.
+func encodeItem[T comparable](e *encoder, i *Item[T], length int, field string, decode_func func(string) (*Item[T], error), encode_func func(T) string) *encodedItem {
+
+	ei := &encodedItem{
+		length: length,
+	}
+
+	if i == nil || e.err != nil {
+		return ei
+	}
+
+	enc := ""
+
+	if i.Raw != "" {
+
+		d, err := decode_func(i.Raw)
+
+		if err != nil || d == nil || d.Value == i.Value {
+			enc = i.Raw
+		}
+	}
+
+	if enc == "" {
+		enc = encode_func(i.Value)
+	}
+
+	if len(enc) > length {
+		err := fmt.Errorf("%s can not be longer than %d characters, got '%s'", field, length, enc)
+		e.err = e.fail(err, ERROR_FIELD_OVERFLOW, field, enc)
+		return ei
+	}
+
+	ei.raw = rightPad(enc, length)
+	return ei
+}
+
+// joinItems concatenates 'items', encoding absent items as spaces, up to and including the last item which is
+// present.
+func joinItems(items []*encodedItem) string {
+
+	last := -1
+
+	for idx, i := range items {
+
+		if i.raw != "" {
+			last = idx
+		}
+	}
+
+	var sb strings.Builder
+
+	for _, i := range items[0 : last+1] {
+
+		if i.raw == "" {
+			sb.WriteString(strings.Repeat(" ", i.length))
+		} else {
+			sb.WriteString(i.raw)
+		}
+	}
+
+	return sb.String()
+}
+
+func encodeString(v string) string {
+	return v
+}
+
+func encodeIssueDate(v IssueDate) string {
+
+	if v == (IssueDate{}) {
+		return ""
+	}
+
+	return fmt.Sprintf("%d%03d", v.YearDigit, v.DayOfYear)
+}
+
+func encodeBaggageTag(v BaggageTag) string {
+
+	if v == (BaggageTag{}) {
+		return ""
+	}
+
+	return fmt.Sprintf("%s%s%s%03d", v.LeadingDigit, v.AirlineNumericCode, v.SerialNumber, v.ConsecutiveTags)
+}
+
+func encodeFastTrack(v bool) string {
+
+	if v {
+		return "Y"
+	}
+
+	return "N"
+}
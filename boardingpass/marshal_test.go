@@ -0,0 +1,235 @@
+package boardingpass
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// TestMarshal encodes legs built from unpadded values and ensures that each field is padded to its fixed width,
+// that the number of legs and field sizes are computed and that values which can not be encoded are rejected.
+func TestMarshal(t *testing.T) {
+
+	leg_1 := &bcbp.Leg{
+		FormatCode:                 "M",
+		PassengerName:              "DESMARAIS/LUC",
+		ElectronicTicketIndicator:  "E",
+		OperatingCarrierPNR:        "ABC123",
+		FromAirport:                "YUL",
+		ToAirport:                  "FRA",
+		OperatingCarrierDesignator: "AC",
+		FlightNumber:               "834",
+		DateOfFlight:               "26",
+		CompartmentCode:            "F",
+		SeatNumber:                 "1A",
+		CheckInSequenceNumber:      "25",
+		PassengerStatus:            "1",
+	}
+
+	leg_2 := &bcbp.Leg{
+		OperatingCarrierPNR:        "DEF456",
+		FromAirport:                "FRA",
+		ToAirport:                  "GVA",
+		OperatingCarrierDesignator: "LH",
+		FlightNumber:               "3664",
+		DateOfFlight:               "227",
+		CompartmentCode:            "C",
+		SeatNumber:                 "12C",
+		CheckInSequenceNumber:      "2",
+		PassengerStatus:            "1",
+	}
+
+	overflow := *leg_2
+	overflow.OperatingCarrierPNR = "ABCD12345"
+
+	tests := []struct {
+		name string
+		legs []*bcbp.Leg
+		opts *MarshalOptions
+		// The expected BCBP string, or "" if an error is expected.
+		expected string
+		// The expected error code and field.
+		code  string
+		field string
+	}{
+		{"single_leg", []*bcbp.Leg{leg_1}, &MarshalOptions{}, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 026F001A0025 100", "", ""},
+		{"multi_leg", []*bcbp.Leg{leg_1, leg_2}, &MarshalOptions{}, "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 026F001A0025 100DEF456 FRAGVALH 3664 227C012C0002 100", "", ""},
+		{"group_separator", []*bcbp.Leg{leg_1, leg_2}, &MarshalOptions{GroupSeparator: true}, "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 026F001A0025 100\x1dM2DESMARAIS/LUC       EDEF456 FRAGVALH 3664 227C012C0002 100", "", ""},
+		{"field_overflow", []*bcbp.Leg{leg_1, &overflow}, &MarshalOptions{}, "", ERROR_FIELD_OVERFLOW, "operating_carrier_pnr"},
+		{"too_many_legs", []*bcbp.Leg{leg_1, leg_2, leg_2, leg_2, leg_2}, &MarshalOptions{}, "", ERROR_FIELD_OVERFLOW, ""},
+		{"missing_leg", []*bcbp.Leg{leg_1, nil}, &MarshalOptions{}, "", ERROR_FIELD_INVALID, ""},
+		{"no_legs", []*bcbp.Leg{}, &MarshalOptions{}, "", ERROR_EMPTY_INPUT, ""},
+	}
+
+	for _, tt := range tests {
+
+		b := &BCBP{
+			Legs: make([]*Leg, len(tt.legs)),
+		}
+
+		for idx, l := range tt.legs {
+
+			if l != nil {
+				b.Legs[idx] = &Leg{Leg: l}
+			}
+		}
+
+		enc, err := MarshalWithOptions(b, tt.opts)
+
+		if tt.expected == "" {
+
+			var e *Error
+
+			switch {
+			case err == nil:
+				t.Errorf("%s: expected an error, got '%s'", tt.name, enc)
+			case !errors.As(err, &e):
+				t.Errorf("%s: expected an Error, got %v", tt.name, err)
+			case e.Code != tt.code || (tt.field != "" && e.Field != tt.field):
+				t.Errorf("%s: expected a %s error for '%s', got %s for '%s'", tt.name, tt.code, tt.field, e.Code, e.Field)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to marshal, %v", tt.name, err)
+			continue
+		}
+
+		if enc != tt.expected {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.expected, enc)
+		}
+	}
+}
+
+// TestMarshalConditional encodes conditional sections built from values and ensures that absent items are encoded
+// as spaces, that sections end with their last item and that field sizes are computed.
+func TestMarshalConditional(t *testing.T) {
+
+	s := func(v string) *Item[string] {
+		return &Item[string]{Value: v}
+	}
+
+	full := &Conditional{
+		VersionNumber:                          &Item[int]{Value: 6},
+		PassengerDescription:                   s("1"),
+		SourceOfCheckIn:                        s("W"),
+		SourceOfBoardingPassIssuance:           s("W"),
+		DateOfIssue:                            &Item[IssueDate]{Value: IssueDate{6, 225}},
+		DocumentType:                           s("B"),
+		AirlineDesignatorOfIssuer:              s("AC"),
+		BaggageTagLicensePlate:                 &Item[BaggageTag]{Value: BaggageTag{"0", "014", "123456", 3}},
+		AirlineNumericCode:                     s("014"),
+		DocumentSerialNumber:                   s("1234567890"),
+		InternationalDocumentationVerification: s("1"),
+		MarketingCarrierDesignator:             s("AC"),
+		FrequentFlyerAirlineDesignator:         s("AC"),
+		FrequentFlyerNumber:                    s("1234567890123"),
+		FreeBaggageAllowance:                   s("20K"),
+		FastTrack:                              &Item[bool]{Value: true},
+		AirlineData:                            "LX58Z",
+	}
+
+	tests := []struct {
+		name  string
+		c     *Conditional
+		first bool
+		// The expected variable size field, or "" if an error is expected.
+		expected string
+		// The expected error code and field.
+		code  string
+		field string
+	}{
+		{"first_leg", full, true, ">6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", "", ""},
+		{"subsequent_leg", &Conditional{AirlineNumericCode: s("014"), DocumentSerialNumber: s("0987654321"), FreeBaggageAllowance: s("2PC")}, false, "29014" + "0987654321" + strings.Repeat(" ", 25) + "2PC", "", ""},
+		{"truncated_unique", &Conditional{VersionNumber: &Item[int]{Value: 6}, PassengerDescription: s("1")}, true, ">6011", "", ""},
+		{"raw_retained", &Conditional{DocumentSerialNumber: &Item[string]{Value: "987654321", Raw: " 987654321"}}, false, "0D    987654321", "", ""},
+		{"airline_data", &Conditional{AirlineData: "LX58Z"}, true, "LX58Z", "", ""},
+		{"missing_version_number", &Conditional{PassengerDescription: s("1")}, true, "", ERROR_FIELD_INVALID, "version_number"},
+		{"field_overflow", &Conditional{FreeBaggageAllowance: s("20KG")}, false, "", ERROR_FIELD_OVERFLOW, "free_baggage_allowance"},
+	}
+
+	for _, tt := range tests {
+
+		enc, err := MarshalConditional(tt.c, tt.first)
+
+		if tt.expected == "" {
+
+			var e *Error
+
+			switch {
+			case err == nil:
+				t.Errorf("%s: expected an error, got '%s'", tt.name, enc)
+			case !errors.As(err, &e):
+				t.Errorf("%s: expected an Error, got %v", tt.name, err)
+			case e.Code != tt.code || e.Field != tt.field:
+				t.Errorf("%s: expected a %s error for '%s', got %s for '%s'", tt.name, tt.code, tt.field, e.Code, e.Field)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to marshal, %v", tt.name, err)
+			continue
+		}
+
+		if enc != tt.expected {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.expected, enc)
+		}
+	}
+}
+
+// TestMarshalSecurity encodes security data sections from their data or signature and ensures that the length of
+// security data is computed.
+func TestMarshalSecurity(t *testing.T) {
+
+	tests := []struct {
+		name string
+		s    *Security
+		// The expected security data section, or "" if an error is expected.
+		expected string
+		// The expected error code.
+		code string
+	}{
+		{"data", &Security{Type: "1", Data: "DEADBEEF"}, "^108DEADBEEF", ""},
+		{"hex_signature", &Security{Type: "1", Signature: []byte{0xde, 0xad}}, "^104DEAD", ""},
+		{"base64_signature", &Security{Type: "2", Encoding: "base64", Signature: []byte("Hello")}, "^208SGVsbG8=", ""},
+		{"empty", &Security{Type: "1"}, "^100", ""},
+		{"missing_type", &Security{Data: "DEADBEEF"}, "", ERROR_FIELD_INVALID},
+		{"data_overflow", &Security{Type: "1", Signature: make([]byte, 128)}, "", ERROR_FIELD_OVERFLOW},
+	}
+
+	for _, tt := range tests {
+
+		enc, err := MarshalSecurity(tt.s)
+
+		if tt.expected == "" {
+
+			var e *Error
+
+			switch {
+			case err == nil:
+				t.Errorf("%s: expected an error, got '%s'", tt.name, enc)
+			case !errors.As(err, &e):
+				t.Errorf("%s: expected an Error, got %v", tt.name, err)
+			case e.Code != tt.code:
+				t.Errorf("%s: expected a %s error, got %s", tt.name, tt.code, e.Code)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to marshal, %v", tt.name, err)
+			continue
+		}
+
+		if enc != tt.expected {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.expected, enc)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package boardingpass
+
+import (
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// TITLE_MR is the title, or honorific, "MR" (mister).
+const TITLE_MR string = "MR"
+
+// TITLE_MRS is the title, or honorific, "MRS" (missus).
+const TITLE_MRS string = "MRS"
+
+// TITLE_MS is the title, or honorific, "MS".
+const TITLE_MS string = "MS"
+
+// TITLE_DR is the title, or honorific, "DR" (doctor).
+const TITLE_DR string = "DR"
+
+// TITLE_MSTR is the title, or honorific, "MSTR" (master) used for boys.
+const TITLE_MSTR string = "MSTR"
+
+// TITLE_MISS is the title, or honorific, "MISS".
+const TITLE_MISS string = "MISS"
+
+// MARKER_INFANT is appended to the given names of a passenger to indicate an infant.
+const MARKER_INFANT string = "INF"
+
+// MARKER_CHILD is appended to the given names of a passenger to indicate a child.
+const MARKER_CHILD string = "CHD"
+
+// titles are the titles which may follow the given names of a passenger, separated by a space.
+var titles = []string{
+	TITLE_MR,
+	TITLE_MRS,
+	TITLE_MS,
+	TITLE_DR,
+	TITLE_MSTR,
+	TITLE_MISS,
+}
+
+// joinedTitles are the titles which may be appended to the given names of a passenger without a space, longest
+// first. "MS" and "DR" are excluded because too many given names end with those letters (for example "ALEKSANDR").
+var joinedTitles = []string{
+	TITLE_MSTR,
+	TITLE_MISS,
+	TITLE_MRS,
+	TITLE_MR,
+}
+
+// PassengerName is the passenger name of a BCBP string decomposed in to its parts.
+type PassengerName struct {
+	// The passenger name as it was encoded.
+	Raw string `json:"raw"`
+	// The characters preceding the first "/".
+	Surname string `json:"surname"`
+	// The characters following the first "/", excluding any titles and markers.
+	GivenNames string `json:"given_names"`
+	// Any titles, or honorifics, following the given names. Zero or more of the TITLE_ constants.
+	Titles []string `json:"titles,omitempty"`
+	// Whether or not the name is followed by the infant marker.
+	Infant bool `json:"infant"`
+	// Whether or not the name is followed by the child marker.
+	Child bool `json:"child"`
+	// Whether or not the name fills the passenger name field and was therefore probably truncated.
+	Truncated bool `json:"truncated"`
+}
+
+// Passenger returns the passenger name of 'b', encoded in the first leg, decomposed in to its parts or nil if 'b'
+// has no legs.
+func (b *BCBP) Passenger() *PassengerName {
+
+	if len(b.Legs) == 0 || b.Legs[0].Leg == nil {
+		return nil
+	}
+
+	return ParsePassengerName(b.Legs[0].PassengerName)
+}
+
+// ParsePassengerName decomposes 'raw', a passenger name in the form "SURNAME/GIVEN NAMES TITLE", in to its parts.
+// Titles and the infant and child markers are removed from the end of the given names, in any order. Titles which
+// are appended to the given names without a space are also removed unless they are the only characters remaining.
+// Names without a "/" are assumed to be a surname.
+func ParsePassengerName(raw string) *PassengerName {
+
+	name := strings.TrimSpace(raw)
+
+	n := &PassengerName{
+		Raw:       raw,
+		Titles:    make([]string, 0),
+		Truncated: len(name) >= bcbp.PASSENGER_NAME,
+	}
+
+	surname, given, ok := strings.Cut(name, "/")
+
+	n.Surname = strings.TrimSpace(surname)
+
+	if !ok {
+		return n
+	}
+
+	words := strings.Fields(given)
+
+	for len(words) > 0 {
+
+		last := words[len(words)-1]
+
+		switch {
+		case last == MARKER_INFANT:
+			n.Infant = true
+		case last == MARKER_CHILD:
+			n.Child = true
+		case isTitle(last):
+			n.Titles = append([]string{last}, n.Titles...)
+		default:
+
+			prefix, t := splitTitle(last)
+
+			if t != "" {
+				n.Titles = append([]string{t}, n.Titles...)
+				words[len(words)-1] = prefix
+			}
+
+			n.GivenNames = strings.Join(words, " ")
+			return n
+		}
+
+		words = words[0 : len(words)-1]
+	}
+
+	return n
+}
+
+// isTitle returns true if 'word' is one of the TITLE_ constants.
+func isTitle(word string) bool {
+
+	for _, t := range titles {
+
+		if word == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitTitle returns 'word' without a title appended to it, and the title, or 'word' and an empty string if it
+// does not end in a title.
+func splitTitle(word string) (string, string) {
+
+	for _, t := range joinedTitles {
+
+		prefix, ok := strings.CutSuffix(word, t)
+
+		if ok && len(prefix) > 1 {
+			return prefix, t
+		}
+	}
+
+	return word, ""
+}
@@ -0,0 +1,63 @@
+package boardingpass
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestParsePassengerName decomposes passenger names with titles, markers and truncated given names.
+func TestParsePassengerName(t *testing.T) {
+
+	tests := []struct {
+		raw         string
+		surname     string
+		given_names string
+		titles      []string
+		infant      bool
+		child       bool
+		truncated   bool
+	}{
+		{"DESMARAIS/LUC       ", "DESMARAIS", "LUC", []string{}, false, false, false},
+		{"DESMARAIS/LUC MR", "DESMARAIS", "LUC", []string{TITLE_MR}, false, false, false},
+		{"DESMARAIS/LUCMR", "DESMARAIS", "LUC", []string{TITLE_MR}, false, false, false},
+		{"SMITH/JANEMRS", "SMITH", "JANE", []string{TITLE_MRS}, false, false, false},
+		{"SMITH/JOHN DR MR", "SMITH", "JOHN", []string{TITLE_DR, TITLE_MR}, false, false, false},
+		{"SMITH/ALEKSANDR", "SMITH", "ALEKSANDR", []string{}, false, false, false},
+		{"SMITH/AMR", "SMITH", "AMR", []string{}, false, false, false},
+		{"SMITH/MR", "SMITH", "", []string{TITLE_MR}, false, false, false},
+		{"SMITH/BABY INF", "SMITH", "BABY", []string{}, true, false, false},
+		{"SMITH/TOMMSTR INF", "SMITH", "TOM", []string{TITLE_MSTR}, true, false, false},
+		{"SMITH/ANNA CHD MISS", "SMITH", "ANNA", []string{TITLE_MISS}, false, true, false},
+		{"DESMARAIS", "DESMARAIS", "", []string{}, false, false, false},
+		{"VANDERBERGHE/ALEXAND", "VANDERBERGHE", "ALEXAND", []string{}, false, false, true},
+	}
+
+	for _, tt := range tests {
+
+		n := ParsePassengerName(tt.raw)
+
+		if n.Raw != tt.raw {
+			t.Errorf("'%s': expected raw name '%s', got '%s'", tt.raw, tt.raw, n.Raw)
+		}
+
+		if n.Surname != tt.surname {
+			t.Errorf("'%s': expected surname '%s', got '%s'", tt.raw, tt.surname, n.Surname)
+		}
+
+		if n.GivenNames != tt.given_names {
+			t.Errorf("'%s': expected given names '%s', got '%s'", tt.raw, tt.given_names, n.GivenNames)
+		}
+
+		if !slices.Equal(n.Titles, tt.titles) {
+			t.Errorf("'%s': expected titles %v, got %v", tt.raw, tt.titles, n.Titles)
+		}
+
+		if n.Infant != tt.infant || n.Child != tt.child {
+			t.Errorf("'%s': expected infant %t and child %t, got %t and %t", tt.raw, tt.infant, tt.child, n.Infant, n.Child)
+		}
+
+		if n.Truncated != tt.truncated {
+			t.Errorf("'%s': expected truncated %t, got %t", tt.raw, tt.truncated, n.Truncated)
+		}
+	}
+}
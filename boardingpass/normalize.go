@@ -0,0 +1,347 @@
+package boardingpass
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CHANGE_CASE is reported when lower case letters in a field have been converted to upper case.
+const CHANGE_CASE string = "CASE"
+
+// CHANGE_PADDING is reported when the spaces or leading zeros used to pad a field to its fixed width have changed.
+const CHANGE_PADDING string = "PADDING"
+
+// CHANGE_FIELD_SIZE is reported when a field size has been recomputed.
+const CHANGE_FIELD_SIZE string = "FIELD_SIZE"
+
+// CHANGE_VALUE is reported when the value of a field has changed, for example the number of legs.
+const CHANGE_VALUE string = "VALUE"
+
+// CHANGE_LEG_SEPARATOR is reported when legs separated by the ASCII group separator character have been
+// concatenated.
+const CHANGE_LEG_SEPARATOR string = "LEG_SEPARATOR"
+
+// CHANGE_TRAILING_WHITESPACE is reported when line breaks, tabs or spaces following a BCBP string have been removed.
+const CHANGE_TRAILING_WHITESPACE string = "TRAILING_WHITESPACE"
+
+// MAX_NORMALIZE_PASSES is the maximum number of times a BCBP string is parsed and re-encoded to derive its canonical
+// form.
+const MAX_NORMALIZE_PASSES int = 8
+
+// Change describes a difference between a BCBP string and its canonical form.
+type Change struct {
+	// A machine-readable code for the change. One of the CHANGE_ or WARNING_ constants. Problems which were
+	// repaired use the same code as the corresponding warning.
+	Code string `json:"code"`
+	// The name of the field, or conditional item, that changed. This may be empty if the change is not specific
+	// to a field.
+	Field string `json:"field"`
+	// The offset of the field in the original BCBP string or -1 if the change is not specific to a location.
+	Offset int `json:"offset"`
+	// The index of the leg that changed or -1 if the change is not specific to a leg.
+	LegIndex int `json:"leg_index"`
+	// The original characters.
+	From string `json:"from"`
+	// The canonical characters.
+	To string `json:"to"`
+	// A human-readable description of the change.
+	Message string `json:"message"`
+}
+
+// Normalize returns the canonical form of 'raw' along with a list of the changes made to derive it. In the canonical
+// form legs are concatenated as described by IATA Resolution 792, letters are upper case, numeric fields are padded
+// with leading zeros, all other fields are padded with trailing spaces and every field size is computed from the
+// data that follows it. 'raw' is parsed in lenient mode so problems that can be repaired are repaired and included
+// in the list of changes. Airline data and security data are never modified.
+func Normalize(raw string) (string, []*Change, error) {
+
+	changes := make([]*Change, 0)
+
+	trimmed := strings.TrimRight(raw, "\r\n\t")
+
+	if trimmed != raw {
+
+		c := &Change{
+			Code:     CHANGE_TRAILING_WHITESPACE,
+			Offset:   len(trimmed),
+			LegIndex: -1,
+			From:     raw[len(trimmed):],
+			Message:  "Removed trailing line breaks and tabs",
+		}
+
+		changes = append(changes, c)
+	}
+
+	opts := &UnmarshalOptions{
+		Lenient:  true,
+		FieldMap: true,
+	}
+
+	b, err := UnmarshalWithOptions(trimmed, opts)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to parse BCBP string, %w", err)
+	}
+
+	// Field maps are replaced when the canonical string is parsed so keep a copy of the originals
+
+	original := fieldMaps(b)
+	warnings := b.Warnings
+	strategy := b.Strategy
+
+	// Parsing a canonical string may repair it again, for example by realigning fields which were misaligned in
+	// 'raw', so the canonical form is the first string which is unchanged by being parsed and re-encoded. This
+	// ensures that normalizing a canonical string returns it as-is
+
+	input := raw
+	canonical := ""
+
+	for pass := 0; ; pass++ {
+
+		if pass == MAX_NORMALIZE_PASSES {
+			return "", nil, fmt.Errorf("Failed to derive canonical BCBP string after %d passes", pass)
+		}
+
+		for _, l := range b.Legs {
+			canonicaliseLeg(l)
+		}
+
+		canonical, err = Marshal(b)
+
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to encode canonical BCBP string, %w", err)
+		}
+
+		if canonical == input {
+			break
+		}
+
+		input = canonical
+
+		b, err = UnmarshalWithOptions(strings.TrimRight(canonical, "\r\n\t"), opts)
+
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to parse canonical BCBP string, %w", err)
+		}
+	}
+
+	if strategy == STRATEGY_GROUP_SEPARATOR {
+
+		c := &Change{
+			Code:     CHANGE_LEG_SEPARATOR,
+			Offset:   -1,
+			LegIndex: -1,
+			Message:  "Concatenated legs separated by the ASCII group separator character",
+		}
+
+		changes = append(changes, c)
+	}
+
+	diffs := diffFieldMaps(original, fieldMaps(b))
+
+	changed := make(map[string]bool)
+
+	for _, c := range diffs {
+		changed[flaggedKey(c.LegIndex, c.Field)] = true
+	}
+
+	for _, w := range warnings {
+
+		// Undefined items, and items which can not be decoded, are left as-is
+
+		if w.Code == WARNING_ITEM_UNDEFINED || w.Code == WARNING_ITEM_INVALID {
+			continue
+		}
+
+		if w.Field != "" && changed[flaggedKey(w.LegIndex, w.Field)] {
+			continue
+		}
+
+		code := w.Code
+
+		// Trailing data which is only whitespace is reported as such
+
+		if code == WARNING_TRAILING_DATA && strings.TrimSpace(w.Raw) == "" {
+			code = CHANGE_TRAILING_WHITESPACE
+		}
+
+		c := &Change{
+			Code:     code,
+			Field:    w.Field,
+			Offset:   w.Offset,
+			LegIndex: w.LegIndex,
+			From:     w.Raw,
+			Message:  w.Message,
+		}
+
+		changes = append(changes, c)
+	}
+
+	changes = append(changes, diffs...)
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Offset < changes[j].Offset
+	})
+
+	return canonical, changes, nil
+}
+
+// canonicaliseLeg converts the letters in the mandatory fields and conditional items of 'l' to upper case and
+// discards the raw characters of conditional items which can be derived from their values.
+func canonicaliseLeg(l *Leg) {
+
+	l.FormatCode = "M"
+
+	for _, v := range []*string{
+		&l.PassengerName,
+		&l.ElectronicTicketIndicator,
+		&l.OperatingCarrierPNR,
+		&l.FromAirport,
+		&l.ToAirport,
+		&l.OperatingCarrierDesignator,
+		&l.FlightNumber,
+		&l.CompartmentCode,
+		&l.SeatNumber,
+		&l.CheckInSequenceNumber,
+		&l.PassengerStatus,
+	} {
+		*v = strings.ToUpper(*v)
+	}
+
+	c := l.Conditional
+
+	if c == nil {
+		return
+	}
+
+	for _, i := range []*Item[string]{
+		c.PassengerDescription,
+		c.SourceOfCheckIn,
+		c.SourceOfBoardingPassIssuance,
+		c.DocumentType,
+		c.AirlineDesignatorOfIssuer,
+		c.AirlineNumericCode,
+		c.DocumentSerialNumber,
+		c.SelecteeIndicator,
+		c.InternationalDocumentationVerification,
+		c.MarketingCarrierDesignator,
+		c.FrequentFlyerAirlineDesignator,
+		c.FrequentFlyerNumber,
+		c.IDADIndicator,
+		c.FreeBaggageAllowance,
+	} {
+		if i != nil {
+			i.Value = strings.ToUpper(i.Value)
+			i.Raw = ""
+		}
+	}
+
+	canonicaliseItem(c.VersionNumber, intItem)
+	canonicaliseItem(c.DateOfIssue, issueDateItem)
+	canonicaliseItem(c.BaggageTagLicensePlate, baggageTagItem)
+	canonicaliseItem(c.FirstNonConsecutiveBaggageTag, baggageTagItem)
+	canonicaliseItem(c.SecondNonConsecutiveBaggageTag, baggageTagItem)
+	canonicaliseItem(c.FastTrack, fastTrackItem)
+}
+
+// canonicaliseItem discards the raw characters of 'i' if they can be decoded, using 'decode_func', so that 'i'
+// will be encoded from its value. Raw characters which can not be decoded, or which are blank, are retained.
+func canonicaliseItem[T any](i *Item[T], decode_func func(string) (*Item[T], error)) {
+
+	if i == nil || strings.TrimSpace(i.Raw) == "" {
+		return
+	}
+
+	_, err := decode_func(i.Raw)
+
+	if err == nil {
+		i.Raw = ""
+	}
+}
+
+// fieldMaps returns the field maps of each leg in 'b', keyed by leg index, with the security data section
+// included in the last leg.
+func fieldMaps(b *BCBP) [][]*FieldLocation {
+
+	maps := make([][]*FieldLocation, len(b.Legs))
+
+	for idx, l := range b.Legs {
+		maps[idx] = l.FieldMap
+	}
+
+	if b.Security != nil && len(maps) > 0 {
+		last := len(maps) - 1
+		maps[last] = append(append([]*FieldLocation{}, maps[last]...), b.Security.FieldMap...)
+	}
+
+	return maps
+}
+
+// diffFieldMaps returns the changes between the field maps of the original BCBP string, 'original', and the
+// canonical BCBP string, 'canonical'.
+func diffFieldMaps(original [][]*FieldLocation, canonical [][]*FieldLocation) []*Change {
+
+	changes := make([]*Change, 0)
+
+	for idx, fm := range original {
+
+		if idx >= len(canonical) {
+			break
+		}
+
+		after := make(map[string]*FieldLocation)
+
+		for _, loc := range canonical[idx] {
+			after[loc.Field] = loc
+		}
+
+		for _, loc := range fm {
+
+			to, ok := after[loc.Field]
+
+			if !ok || to.Raw == loc.Raw {
+				continue
+			}
+
+			code := changeCode(loc.Field, loc.Raw, to.Raw)
+
+			c := &Change{
+				Code:     code,
+				Field:    loc.Field,
+				Offset:   loc.Offset,
+				LegIndex: idx,
+				From:     loc.Raw,
+				To:       to.Raw,
+				Message:  fmt.Sprintf("Changed %s from '%s' to '%s'", loc.Field, loc.Raw, to.Raw),
+			}
+
+			changes = append(changes, c)
+		}
+	}
+
+	return changes
+}
+
+// changeCode returns the CHANGE_ constant that best describes the change of 'field' from 'from' to 'to'.
+func changeCode(field string, from string, to string) string {
+
+	switch field {
+	case "optional_data_size", "unique_field_size", "repeated_field_size", "length_of_security_data":
+		return CHANGE_FIELD_SIZE
+	}
+
+	if strings.EqualFold(from, to) {
+		return CHANGE_CASE
+	}
+
+	unpad := func(s string) string {
+		return strings.TrimLeft(strings.TrimSpace(strings.ToUpper(s)), "0")
+	}
+
+	if unpad(from) == unpad(to) {
+		return CHANGE_PADDING
+	}
+
+	return CHANGE_VALUE
+}
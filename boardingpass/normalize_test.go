@@ -0,0 +1,139 @@
+package boardingpass
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNormalize derives the canonical form of BCBP strings which differ from it in a single way and ensures that
+// the difference is reported.
+func TestNormalize(t *testing.T) {
+
+	canonical := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100"
+
+	tests := []struct {
+		name      string
+		raw       string
+		canonical string
+		// The expected change, and the field that changed, or "" if the field is not specific.
+		code  string
+		field string
+	}{
+		{"space_padded_flight_number", "M1DESMARAIS/LUC       EABC123 YULFRAAC 834  226F001A0025 100", canonical, CHANGE_PADDING, "flight_number"},
+		{"unpadded_seat", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F1A  0025 100", canonical, CHANGE_PADDING, "seat_number"},
+		{"unpadded_checkin_sequence_number", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A25   100", canonical, CHANGE_PADDING, "checkin_sequence_number"},
+		{"lower_case", "M1desmarais/luc       EABC123 YULFRAAC 0834 226F001A0025 100", canonical, CHANGE_CASE, "passenger_name"},
+		{"trailing_line_break", canonical + "\r\n", canonical, CHANGE_TRAILING_WHITESPACE, ""},
+		{"trailing_spaces", canonical + "   ", canonical, CHANGE_TRAILING_WHITESPACE, ""},
+		{"number_of_legs", "M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100", canonical, CHANGE_VALUE, "number_of_legs"},
+		{"field_size", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14E>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z", CHANGE_FIELD_SIZE, "optional_data_size"},
+		{"leg_separator", canonical[0:1] + "2" + canonical[2:] + "\x1dM2DESMARAIS/LUC       EDEF456 FRAGVALH 3664 227C012C0002 100", canonical[0:1] + "2" + canonical[2:] + "DEF456 FRAGVALH 3664 227C012C0002 100", CHANGE_LEG_SEPARATOR, ""},
+		{"passenger_name_truncated", "M1DESMARAIS/LUC EABC123 YULFRAAC 0834 226F001A0025 100", canonical, CHANGE_PADDING, "passenger_name"},
+		{"stray_characters", canonical + "Z^108ABCDEFGH", canonical + "^108ABCDEFGH", WARNING_TRAILING_DATA, ""},
+	}
+
+	for _, tt := range tests {
+
+		c, changes, err := Normalize(tt.raw)
+
+		if err != nil {
+			t.Errorf("%s: failed to normalize, %v", tt.name, err)
+			continue
+		}
+
+		if c != tt.canonical {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.canonical, c)
+		}
+
+		found := false
+
+		for _, ch := range changes {
+
+			if ch.Code == tt.code && ch.Field == tt.field {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("%s: expected a %s change to '%s', got %d changes", tt.name, tt.code, tt.field, len(changes))
+		}
+
+		_, changes, err = Normalize(c)
+
+		if err != nil || len(changes) != 0 {
+			t.Errorf("%s: expected the canonical string to be unchanged, got %d changes (%v)", tt.name, len(changes), err)
+		}
+	}
+}
+
+// TestNormalizePreserved ensures that airline data and security data are never modified, even if they contain lower
+// case letters, and that items which are not defined by the version are left as-is.
+func TestNormalizePreserved(t *testing.T) {
+
+	raw := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>3181ww6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYlx58z^108abcdefgh"
+	expected := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYlx58z^108abcdefgh"
+
+	c, changes, err := Normalize(raw)
+
+	if err != nil {
+		t.Fatalf("Failed to normalize, %v", err)
+	}
+
+	if c != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, c)
+	}
+
+	for _, ch := range changes {
+
+		if ch.Code != CHANGE_CASE {
+			t.Errorf("Unexpected %s change to '%s'", ch.Code, ch.Field)
+		}
+	}
+}
+
+// TestNormalizeIdempotent ensures that normalizing the canonical form of a BCBP string does not change it, including
+// strings which have been truncated or have had a character removed so that their fields are misaligned.
+func TestNormalizeIdempotent(t *testing.T) {
+
+	seeds := []string{
+		"M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14C>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z^108ABCDEFGH",
+		"M2DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 13C>5181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58ZDEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ^108ABCDEFGH",
+	}
+
+	inputs := []string{
+		"M2DESMARAIS/LUC       EAC13 YULFRAAC 0834 226F",
+		"M1DESMARAIS/LUC       EABC23 YULF AAC 0834 226F001A0025 100",
+		"M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>3181WW6225BC 001412345600^32A0141234567890 1AC AC 1234567890123  ",
+	}
+
+	for _, s := range seeds {
+
+		for i := 1; i <= len(s); i++ {
+			inputs = append(inputs, s[0:i])
+		}
+
+		for i := 0; i < len(s); i++ {
+			inputs = append(inputs, s[0:i]+s[i+1:], s[0:i]+"\x1d"+s[i:], s[0:i]+strings.ToLower(s[i:]))
+		}
+	}
+
+	for _, raw := range inputs {
+
+		c, _, err := Normalize(raw)
+
+		if err != nil {
+			continue
+		}
+
+		c2, changes, err := Normalize(c)
+
+		if err != nil {
+			t.Errorf("'%s': failed to normalize canonical string '%s', %v", raw, c, err)
+			continue
+		}
+
+		if c2 != c || len(changes) != 0 {
+			t.Errorf("'%s': expected canonical string '%s' to be unchanged, got '%s' (%d changes)", raw, c, c2, len(changes))
+		}
+	}
+}
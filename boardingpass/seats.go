@@ -0,0 +1,171 @@
+package boardingpass
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SEAT_STATUS_ASSIGNED indicates that a seat number is a row and a letter.
+const SEAT_STATUS_ASSIGNED string = "assigned"
+
+// SEAT_STATUS_INFANT indicates that a seat number is "INF", an infant without a seat of their own.
+const SEAT_STATUS_INFANT string = "infant"
+
+// SEAT_STATUS_GATE indicates that a seat number is "GATE", a seat which will be assigned at the gate.
+const SEAT_STATUS_GATE string = "gate"
+
+// SEAT_STATUS_STANDBY indicates that a seat number is "STBY", a standby passenger.
+const SEAT_STATUS_STANDBY string = "standby"
+
+// SEAT_STATUS_UNASSIGNED indicates that a seat number is blank or "000".
+const SEAT_STATUS_UNASSIGNED string = "unassigned"
+
+// SEAT_STATUS_UNKNOWN indicates that a seat number could not be interpreted.
+const SEAT_STATUS_UNKNOWN string = "unknown"
+
+// CABIN_FIRST is the cabin descriptor for first class.
+const CABIN_FIRST string = "first"
+
+// CABIN_BUSINESS is the cabin descriptor for business class.
+const CABIN_BUSINESS string = "business"
+
+// CABIN_PREMIUM_ECONOMY is the cabin descriptor for premium economy.
+const CABIN_PREMIUM_ECONOMY string = "premium_economy"
+
+// CABIN_ECONOMY is the cabin descriptor for economy.
+const CABIN_ECONOMY string = "economy"
+
+// CABIN_UNKNOWN is the cabin descriptor for compartment codes which are not recognised.
+const CABIN_UNKNOWN string = "unknown"
+
+// specialSeats maps the non-numeric seat numbers to a SEAT_STATUS_ constant.
+var specialSeats = map[string]string{
+	"INF":  SEAT_STATUS_INFANT,
+	"GATE": SEAT_STATUS_GATE,
+	"STBY": SEAT_STATUS_STANDBY,
+	"":     SEAT_STATUS_UNASSIGNED,
+}
+
+// cabins maps compartment codes to the cabin they are most commonly used for. Airlines assign compartment codes
+// (which are often the booking class) differently so these can be overridden using `CabinOverrides`.
+var cabins = map[string]string{
+	"F": CABIN_FIRST,
+	"A": CABIN_FIRST,
+	"P": CABIN_FIRST,
+	"R": CABIN_FIRST,
+	"J": CABIN_BUSINESS,
+	"C": CABIN_BUSINESS,
+	"D": CABIN_BUSINESS,
+	"I": CABIN_BUSINESS,
+	"Z": CABIN_BUSINESS,
+	"W": CABIN_PREMIUM_ECONOMY,
+	"E": CABIN_PREMIUM_ECONOMY,
+	"Y": CABIN_ECONOMY,
+	"B": CABIN_ECONOMY,
+	"G": CABIN_ECONOMY,
+	"H": CABIN_ECONOMY,
+	"K": CABIN_ECONOMY,
+	"L": CABIN_ECONOMY,
+	"M": CABIN_ECONOMY,
+	"N": CABIN_ECONOMY,
+	"O": CABIN_ECONOMY,
+	"Q": CABIN_ECONOMY,
+	"S": CABIN_ECONOMY,
+	"T": CABIN_ECONOMY,
+	"U": CABIN_ECONOMY,
+	"V": CABIN_ECONOMY,
+	"X": CABIN_ECONOMY,
+}
+
+// CabinOverrides maps an airline designator to the cabin descriptors for its compartment codes, for example
+// `CabinOverrides{"DL": {"P": CABIN_PREMIUM_ECONOMY}}`.
+type CabinOverrides map[string]map[string]string
+
+// Seat is the seat number of a leg decomposed in to its parts.
+type Seat struct {
+	// The seat number as it was encoded, with leading zeros removed.
+	Raw string `json:"raw"`
+	// The seat row, or 0 if no seat is assigned.
+	Row int `json:"row"`
+	// The seat letter, or an empty string if no seat is assigned.
+	Letter string `json:"letter"`
+	// One of the SEAT_STATUS_ constants.
+	Status string `json:"status"`
+}
+
+// Seat returns the seat number of 'l' decomposed in to its parts. See `ParseSeat` for details.
+func (l *Leg) Seat() *Seat {
+	return ParseSeat(l.SeatNumber)
+}
+
+// Cabin returns the cabin descriptor for the compartment code of 'l'. See `ResolveCabin` for details.
+func (l *Leg) Cabin(overrides CabinOverrides) string {
+	return ResolveCabin(l.OperatingCarrierDesignator, l.CompartmentCode, overrides)
+}
+
+// ParseSeat decomposes 'raw', a seat number, in to a row and letter. Seat numbers of "INF", "GATE" and "STBY"
+// and seat numbers which are blank or "000" are reported using the corresponding SEAT_STATUS_ constant.
+func ParseSeat(raw string) *Seat {
+
+	s := &Seat{
+		Raw:    raw,
+		Status: SEAT_STATUS_UNKNOWN,
+	}
+
+	// Leading zeros are removed by bcbp.ParseLeg but may be present if 'raw' was not parsed
+
+	seat := strings.TrimLeft(strings.ToUpper(strings.TrimSpace(raw)), "0")
+
+	status, ok := specialSeats[seat]
+
+	if ok {
+		s.Status = status
+		return s
+	}
+
+	m := re_seat_number.FindStringSubmatch(seat)
+
+	if m == nil {
+		return s
+	}
+
+	row, err := strconv.Atoi(m[1])
+
+	if err != nil {
+		return s
+	}
+
+	s.Row = row
+	s.Letter = m[2]
+	s.Status = SEAT_STATUS_ASSIGNED
+
+	return s
+}
+
+// ResolveCabin returns the cabin descriptor, one of the CABIN_ constants or a value from 'overrides', for the
+// compartment code 'code' used by the airline 'designator'. Codes which are not overridden by the airline are
+// mapped to the cabin they are most commonly used for.
+func ResolveCabin(designator string, code string, overrides CabinOverrides) string {
+
+	designator = strings.ToUpper(strings.TrimSpace(designator))
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	airline, ok := overrides[designator]
+
+	if ok {
+
+		cabin, ok := airline[code]
+
+		if ok {
+			return cabin
+		}
+	}
+
+	cabin, ok := cabins[code]
+
+	if !ok {
+		return CABIN_UNKNOWN
+	}
+
+	return cabin
+}
@@ -0,0 +1,78 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestParseSeat decomposes padded, unpadded and special seat numbers.
+func TestParseSeat(t *testing.T) {
+
+	tests := []struct {
+		raw    string
+		row    int
+		letter string
+		status string
+	}{
+		{"001A", 1, "A", SEAT_STATUS_ASSIGNED},
+		{"1A", 1, "A", SEAT_STATUS_ASSIGNED},
+		{"12c", 12, "C", SEAT_STATUS_ASSIGNED},
+		{"123K", 123, "K", SEAT_STATUS_ASSIGNED},
+		{"INF", 0, "", SEAT_STATUS_INFANT},
+		{"GATE", 0, "", SEAT_STATUS_GATE},
+		{"STBY", 0, "", SEAT_STATUS_STANDBY},
+		{"000", 0, "", SEAT_STATUS_UNASSIGNED},
+		{"    ", 0, "", SEAT_STATUS_UNASSIGNED},
+		{"A12", 0, "", SEAT_STATUS_UNKNOWN},
+		{"1234", 0, "", SEAT_STATUS_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+
+		s := ParseSeat(tt.raw)
+
+		if s.Raw != tt.raw {
+			t.Errorf("'%s': expected raw seat number '%s', got '%s'", tt.raw, tt.raw, s.Raw)
+		}
+
+		if s.Row != tt.row || s.Letter != tt.letter {
+			t.Errorf("'%s': expected row %d and letter '%s', got %d and '%s'", tt.raw, tt.row, tt.letter, s.Row, s.Letter)
+		}
+
+		if s.Status != tt.status {
+			t.Errorf("'%s': expected status %s, got %s", tt.raw, tt.status, s.Status)
+		}
+	}
+}
+
+// TestResolveCabin maps compartment codes to cabins, with and without airline overrides.
+func TestResolveCabin(t *testing.T) {
+
+	overrides := CabinOverrides{
+		"DL": {"P": CABIN_PREMIUM_ECONOMY},
+	}
+
+	tests := []struct {
+		designator string
+		code       string
+		cabin      string
+	}{
+		{"AC", "F", CABIN_FIRST},
+		{"AC", "j", CABIN_BUSINESS},
+		{"AC", "W", CABIN_PREMIUM_ECONOMY},
+		{"AC", "Y", CABIN_ECONOMY},
+		{"AC", "P", CABIN_FIRST},
+		{"DL ", "P", CABIN_PREMIUM_ECONOMY},
+		{"DL", "Y", CABIN_ECONOMY},
+		{"AC", "1", CABIN_UNKNOWN},
+		{"AC", " ", CABIN_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+
+		cabin := ResolveCabin(tt.designator, tt.code, overrides)
+
+		if cabin != tt.cabin {
+			t.Errorf("'%s' '%s': expected cabin %s, got %s", tt.designator, tt.code, tt.cabin, cabin)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package boardingpass
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+/*
+
+Security Fields (Variable Length)
+These fields follow the variable size field of the last leg.
+
+Field No.	Field Name	Length (Chars)	Notes
+25	Beginning of Security Data	1	"^"
+28	Type of Security Data	1
+29	Length of Security Data	2	Hexadecimal
+30	Security Data	Variable
+
+*/
+
+const BEGINNING_OF_SECURITY_DATA_MARKER string = "^"
+
+const BEGINNING_OF_SECURITY_DATA int = 1
+const TYPE_OF_SECURITY_DATA int = 1
+const LENGTH_OF_SECURITY_DATA int = 2
+
+// Security is the decoded security data section of a BCBP string.
+type Security struct {
+	// The type of security data (the signing algorithm and key) as defined by the issuing carrier.
+	Type string `json:"type"`
+	// The declared length of the security data.
+	Length *Item[int] `json:"length"`
+	// The security data as it appears in the BCBP string.
+	Data string `json:"data"`
+	// The encoding of the security data, if it could be determined. One of "hex" or "base64".
+	Encoding string `json:"encoding,omitempty"`
+	// The signature bytes derived from the security data. If the encoding could not be determined these
+	// will be the bytes of the security data itself.
+	Signature []byte `json:"signature"`
+	// The complete security data section, including the "^" marker and headers.
+	Raw string `json:"raw"`
+	// The location of each field in the security data section, if requested.
+	FieldMap []*FieldLocation `json:"field_map,omitempty"`
+}
+
+// ParseSecurity decodes 'raw' as a security data section. 'raw' is expected to start with the "^" marker and
+// to end with the last character of the security data.
+func ParseSecurity(raw string) (*Security, error) {
+	d := &decoder{}
+	return d.parseSecurity(raw, 0)
+}
+
+// parseSecurity decodes 'raw' as a security data section, where 'base' is the offset of 'raw' in the BCBP string.
+func (d *decoder) parseSecurity(raw string, base int) (*Security, error) {
+
+	if !strings.HasPrefix(raw, BEGINNING_OF_SECURITY_DATA_MARKER) {
+		return nil, fmt.Errorf("Security data must start with %s", BEGINNING_OF_SECURITY_DATA_MARKER)
+	}
+
+	// The locations of the fields in the security data section belong to the section rather than a leg
+
+	start := len(d.locations)
+
+	defer func() {
+		d.locations = d.locations[0:start]
+	}()
+
+	r := &fieldReader{raw: raw, base: base}
+	d.locate("beginning_of_security_data", r.position(), r.next(BEGINNING_OF_SECURITY_DATA), BEGINNING_OF_SECURITY_DATA_MARKER)
+
+	t_offset := r.position()
+	t := r.next(TYPE_OF_SECURITY_DATA)
+
+	if len(t) != TYPE_OF_SECURITY_DATA {
+		return nil, fmt.Errorf("Missing type of security data")
+	}
+
+	d.locate("type_of_security_data", t_offset, t, t)
+
+	data, sz, err := d.section(r, LENGTH_OF_SECURITY_DATA, "length_of_security_data")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read security data, %w", err)
+	}
+
+	data_offset := r.position() - len(data)
+
+	if r.remaining() > 0 {
+
+		offset := r.position()
+		extra := r.rest()
+
+		err := fmt.Errorf("Length of security data (%d) does not match security data (%d)", sz.Value, sz.Value+len(extra))
+
+		err = d.recover(err, WARNING_SECURITY_LENGTH_MISMATCH, "security_data", offset, extra)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = data + extra
+	}
+
+	d.locate("security_data", data_offset, data, data)
+
+	s := &Security{
+		Type:   t,
+		Length: sz,
+		Data:   data,
+		Raw:    raw,
+	}
+
+	if d.field_map {
+		s.FieldMap = append([]*FieldLocation{}, d.locations[start:]...)
+	}
+
+	s.Encoding, s.Signature = decodeSecurityData(data)
+	return s, nil
+}
+
+// SplitSecurity returns the variable size field of 'l' and the security data section that follows it, if present.
+// If the security data section has (incorrectly) been included, in full or in part, in the length declared by the
+// leg's "field size of variable size field" it will be removed from the variable size field that is returned.
+func SplitSecurity(l *bcbp.Leg) (string, *Security, error) {
+
+	variable, err := VariableSizeField(l)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	d := &decoder{}
+	return d.splitSecurity(variable, l.OptionalData[len(variable):], 0)
+}
+
+// splitSecurity returns 'variable' and the security data section in either 'rest' or at the end of 'variable', where
+// 'base' is the offset of 'variable' in the BCBP string and 'rest' is the data that follows it.
+func (d *decoder) splitSecurity(variable string, rest string, base int) (string, *Security, error) {
+
+	if strings.HasPrefix(rest, BEGINNING_OF_SECURITY_DATA_MARKER) {
+
+		s, err := d.parseSecurity(rest, base+len(variable))
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return variable, s, nil
+	}
+
+	// Characters which precede the security data section are reported, and ignored, in both modes
+
+	idx := strings.Index(rest, BEGINNING_OF_SECURITY_DATA_MARKER)
+
+	if idx != -1 {
+
+		d.warn(WARNING_TRAILING_DATA, "", base+len(variable), rest[0:idx], "Ignoring %d characters preceding the security data section", idx)
+
+		s, err := d.parseSecurity(rest[idx:], base+len(variable)+idx)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return variable, s, nil
+	}
+
+	// The security data section may (incorrectly) have been included, in full or in part, in the length declared
+	// by the leg's "field size of variable size field"
+
+	idx = strings.LastIndex(variable, BEGINNING_OF_SECURITY_DATA_MARKER)
+
+	if idx != -1 {
+
+		// Use a strict decoder since a "^" character may simply be part of the airline data
+
+		probe := &decoder{
+			field_map: d.field_map,
+		}
+
+		s, err := probe.parseSecurity(variable[idx:]+rest, base+idx)
+
+		if err == nil {
+			return variable[0:idx], s, nil
+		}
+	}
+
+	if rest != "" {
+		d.warn(WARNING_TRAILING_DATA, "", base+len(variable), rest, "Ignoring %d characters following the last leg", len(rest))
+	}
+
+	return variable, nil, nil
+}
+
+func decodeSecurityData(data string) (string, []byte) {
+
+	if len(data) > 0 && len(data)%2 == 0 {
+
+		b, err := hex.DecodeString(data)
+
+		if err == nil {
+			return "hex", b
+		}
+	}
+
+	encodings := []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	}
+
+	for _, enc := range encodings {
+
+		b, err := enc.DecodeString(data)
+
+		if err == nil {
+			return "base64", b
+		}
+	}
+
+	return "", []byte(data)
+}
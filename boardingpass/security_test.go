@@ -0,0 +1,120 @@
+package boardingpass
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestSecurity parses the security data section following the last leg, in strict and lenient mode, and ensures
+// that it is removed from the optional data of the leg and that the BCBP string can be re-encoded byte for byte.
+func TestSecurity(t *testing.T) {
+
+	bcbp_str := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 100"
+
+	tests := []struct {
+		name    string
+		suffix  string
+		lenient bool
+		// The expected security data and encoding, or "" if no security data is expected.
+		data     string
+		encoding string
+		// The expected decoded signature.
+		signature []byte
+		// The expected warning, or "".
+		warning string
+		// The expected optional data of the last leg.
+		optional_data string
+	}{
+		{"hex", "^108DEADBEEF", false, "DEADBEEF", "hex", []byte{0xde, 0xad, 0xbe, 0xef}, "", ""},
+		{"base64", "^10CSGVsbG8gV29y", false, "SGVsbG8gV29y", "base64", []byte("Hello Wor"), "", ""},
+		{"missing", "", false, "", "", nil, "", ""},
+		{"length_too_long_strict", "^10ADEADBEEF", false, "", "", nil, WARNING_SECURITY_INVALID, "^10ADEADBEEF"},
+		{"length_too_long_lenient", "^10ADEADBEEF", true, "DEADBEEF", "hex", []byte{0xde, 0xad, 0xbe, 0xef}, WARNING_FIELD_SIZE_MISMATCH, ""},
+		{"length_too_short_strict", "^106DEADBEEF", false, "", "", nil, WARNING_SECURITY_INVALID, "^106DEADBEEF"},
+		{"length_too_short_lenient", "^106DEADBEEF", true, "DEADBEEF", "hex", []byte{0xde, 0xad, 0xbe, 0xef}, WARNING_SECURITY_LENGTH_MISMATCH, ""},
+		{"incomplete_strict", "^1", false, "", "", nil, WARNING_SECURITY_INVALID, "^1"},
+		{"stray_characters_strict", "Z^108DEADBEEF", false, "DEADBEEF", "hex", []byte{0xde, 0xad, 0xbe, 0xef}, WARNING_TRAILING_DATA, "Z"},
+		{"stray_characters_lenient", "Z^108DEADBEEF", true, "DEADBEEF", "hex", []byte{0xde, 0xad, 0xbe, 0xef}, WARNING_TRAILING_DATA, "Z"},
+		{"trailing_data", "XYZ", false, "", "", nil, WARNING_TRAILING_DATA, "XYZ"},
+	}
+
+	for _, tt := range tests {
+
+		raw := bcbp_str + tt.suffix
+
+		b, err := UnmarshalWithOptions(raw, &UnmarshalOptions{Lenient: tt.lenient, Lossless: true})
+
+		if err != nil {
+			t.Errorf("%s: failed to parse, %v", tt.name, err)
+			continue
+		}
+
+		if tt.data == "" && b.Security != nil {
+			t.Errorf("%s: unexpected security data '%s'", tt.name, b.Security.Raw)
+		}
+
+		if tt.data != "" {
+
+			switch {
+			case b.Security == nil:
+				t.Errorf("%s: expected security data", tt.name)
+			case b.Security.Data != tt.data || b.Security.Encoding != tt.encoding:
+				t.Errorf("%s: expected %s security data '%s', got %s '%s'", tt.name, tt.encoding, tt.data, b.Security.Encoding, b.Security.Data)
+			case !bytes.Equal(b.Security.Signature, tt.signature):
+				t.Errorf("%s: expected signature %x, got %x", tt.name, tt.signature, b.Security.Signature)
+			}
+		}
+
+		if tt.warning == "" && len(b.Warnings) > 0 {
+			t.Errorf("%s: unexpected warning %s", tt.name, b.Warnings[0].Code)
+		}
+
+		if tt.warning != "" && !hasWarning(b, tt.warning) {
+			t.Errorf("%s: expected a %s warning", tt.name, tt.warning)
+		}
+
+		if b.Legs[0].OptionalData != tt.optional_data {
+			t.Errorf("%s: expected optional data '%s', got '%s'", tt.name, tt.optional_data, b.Legs[0].OptionalData)
+		}
+
+		enc, err := Marshal(b)
+
+		if err != nil {
+			t.Errorf("%s: failed to marshal, %v", tt.name, err)
+			continue
+		}
+
+		if enc != raw {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, raw, enc)
+		}
+	}
+}
+
+// TestSecurityConditional parses a BCBP string whose conditional section is followed by a stray character and
+// then the security data section.
+func TestSecurityConditional(t *testing.T) {
+
+	raw := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14C>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z^108ABCDEFGH"
+
+	for _, opts := range []*UnmarshalOptions{{}, {Lenient: true}} {
+
+		b, err := UnmarshalWithOptions(raw, opts)
+
+		if err != nil {
+			t.Errorf("Lenient %t: failed to parse, %v", opts.Lenient, err)
+			continue
+		}
+
+		if b.Security == nil || b.Security.Raw != "^108ABCDEFGH" {
+			t.Errorf("Lenient %t: expected security data '^108ABCDEFGH', got %v", opts.Lenient, b.Security)
+		}
+
+		if b.Legs[0].Conditional.AirlineData != "LX58" {
+			t.Errorf("Lenient %t: expected airline data 'LX58', got '%s'", opts.Lenient, b.Legs[0].Conditional.AirlineData)
+		}
+
+		if !hasWarning(b, WARNING_TRAILING_DATA) {
+			t.Errorf("Lenient %t: expected a %s warning", opts.Lenient, WARNING_TRAILING_DATA)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package boardingpass
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CARRIER_MATCH indicates that the airline numeric code of a ticket number belongs to the airline it was
+// compared with.
+const CARRIER_MATCH string = "match"
+
+// CARRIER_MISMATCH indicates that the airline numeric code of a ticket number belongs to a different airline
+// than the one it was compared with. This is expected for codeshare flights where the ticket was issued by the
+// marketing carrier.
+const CARRIER_MISMATCH string = "mismatch"
+
+// CARRIER_UNKNOWN indicates that the airline numeric code of a ticket number could not be compared because the
+// airline is not in the built-in table of airline numeric codes.
+const CARRIER_UNKNOWN string = "unknown"
+
+var re_ticket_number = regexp.MustCompile(`^([0-9]{3})([0-9]{10})([0-9]?)$`)
+
+// airlineNumericCodes maps the designators of (mostly larger) airlines to their three digit airline numeric
+// (ticketing or accounting) code.
+var airlineNumericCodes = map[string]string{
+	"A3": "390",
+	"AA": "001",
+	"AC": "014",
+	"AF": "057",
+	"AI": "098",
+	"AM": "139",
+	"AS": "027",
+	"AV": "134",
+	"AY": "105",
+	"AZ": "055",
+	"B6": "279",
+	"BA": "125",
+	"BR": "695",
+	"CA": "999",
+	"CI": "297",
+	"CM": "230",
+	"CX": "160",
+	"CZ": "784",
+	"DL": "006",
+	"EI": "053",
+	"EK": "176",
+	"ET": "071",
+	"EY": "607",
+	"F9": "422",
+	"G4": "268",
+	"HA": "173",
+	"IB": "075",
+	"JL": "131",
+	"KE": "180",
+	"KL": "074",
+	"LA": "045",
+	"LH": "220",
+	"LO": "080",
+	"LX": "724",
+	"MS": "077",
+	"MU": "781",
+	"NH": "205",
+	"NK": "487",
+	"NZ": "086",
+	"OK": "064",
+	"OS": "257",
+	"OZ": "988",
+	"QF": "081",
+	"QR": "157",
+	"SA": "083",
+	"SK": "117",
+	"SN": "082",
+	"SQ": "618",
+	"SV": "065",
+	"SY": "337",
+	"TK": "235",
+	"TP": "047",
+	"UA": "016",
+	"UX": "996",
+	"VS": "932",
+	"WN": "526",
+	"WS": "838",
+}
+
+// Ticket is the 13 digit ticket number assembled from the airline numeric code and document form/serial number
+// conditional items of a leg.
+type Ticket struct {
+	// The 13 digit ticket number.
+	Number string `json:"number"`
+	// The three digit airline numeric code of the airline which issued the ticket.
+	AirlineNumericCode string `json:"airline_numeric_code"`
+	// The ten digit document form/serial number.
+	DocumentSerialNumber string `json:"document_serial_number"`
+	// The check digit, if one was present.
+	CheckDigit string `json:"check_digit,omitempty"`
+	// The mod-7 check digit derived from the document form/serial number.
+	ExpectedCheckDigit string `json:"expected_check_digit"`
+	// Whether or not the check digit, if one was present, matches the expected check digit.
+	CheckDigitValid *bool `json:"check_digit_valid,omitempty"`
+	// The airline designator the airline numeric code was compared with.
+	Carrier string `json:"carrier,omitempty"`
+	// The result of comparing the airline numeric code with 'Carrier'. One of the CARRIER_ constants.
+	CarrierMatch string `json:"carrier_match,omitempty"`
+}
+
+// Ticket returns the ticket number of 'l' cross-checked against its operating carrier designator. It returns nil
+// if the leg has no airline numeric code or document form/serial number, or if either is blank. BCBP strings do not
+// encode the check digit of a ticket number so only the expected check digit is reported.
+func (l *Leg) Ticket() (*Ticket, error) {
+
+	c := l.Conditional
+
+	if c == nil || c.AirlineNumericCode == nil || c.DocumentSerialNumber == nil {
+		return nil, nil
+	}
+
+	if c.AirlineNumericCode.Value == "" || c.DocumentSerialNumber.Value == "" {
+		return nil, nil
+	}
+
+	t, err := ParseTicketNumber(c.AirlineNumericCode.Value + c.DocumentSerialNumber.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.CheckCarrier(l.OperatingCarrierDesignator)
+	return t, nil
+}
+
+// ParseTicketNumber parses 'raw', a 13 digit ticket number optionally followed by a check digit. Spaces and
+// hyphens are ignored. If a check digit is present it is validated against the document form/serial number.
+func ParseTicketNumber(raw string) (*Ticket, error) {
+
+	number := strings.NewReplacer(" ", "", "-", "").Replace(raw)
+
+	m := re_ticket_number.FindStringSubmatch(number)
+
+	if m == nil {
+		return nil, fmt.Errorf("Invalid ticket number '%s', expected 13 digits and an optional check digit", raw)
+	}
+
+	t := &Ticket{
+		Number:               m[1] + m[2],
+		AirlineNumericCode:   m[1],
+		DocumentSerialNumber: m[2],
+		CheckDigit:           m[3],
+		ExpectedCheckDigit:   TicketCheckDigit(m[2]),
+	}
+
+	if t.CheckDigit != "" {
+		valid := t.CheckDigit == t.ExpectedCheckDigit
+		t.CheckDigitValid = &valid
+	}
+
+	return t, nil
+}
+
+// TicketCheckDigit returns the check digit for 'serial', a ten digit document form/serial number: the remainder
+// of 'serial' divided by 7.
+func TicketCheckDigit(serial string) string {
+
+	n, err := strconv.ParseUint(serial, 10, 64)
+
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatUint(n%7, 10)
+}
+
+// CheckCarrier compares the airline numeric code of 't' with the airline numeric code of 'designator', updating
+// the 'Carrier' and 'CarrierMatch' properties of 't'.
+func (t *Ticket) CheckCarrier(designator string) {
+
+	t.Carrier = strings.ToUpper(strings.TrimSpace(designator))
+	t.CarrierMatch = CARRIER_UNKNOWN
+
+	code, ok := airlineNumericCodes[t.Carrier]
+
+	if !ok {
+		return
+	}
+
+	if code == t.AirlineNumericCode {
+		t.CarrierMatch = CARRIER_MATCH
+	} else {
+		t.CarrierMatch = CARRIER_MISMATCH
+	}
+}
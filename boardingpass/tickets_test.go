@@ -0,0 +1,95 @@
+package boardingpass
+
+import (
+	"strconv"
+	"testing"
+)
+
+// TestTicketCheckDigit derives the mod-7 check digit of ticket numbers.
+func TestTicketCheckDigit(t *testing.T) {
+
+	tests := []struct {
+		serial      string
+		check_digit string
+	}{
+		{"2305123456", "6"},
+		{"1234567890", "3"},
+		{"0987654321", "3"},
+		{"2100000000", "0"},
+		{"9999999999", "3"},
+		{"0000000000", "0"},
+		{"12345X7890", ""},
+	}
+
+	for _, tt := range tests {
+
+		d := TicketCheckDigit(tt.serial)
+
+		if d != tt.check_digit {
+			t.Errorf("'%s': expected check digit '%s', got '%s'", tt.serial, tt.check_digit, d)
+		}
+	}
+}
+
+// TestParseTicketNumber parses ticket numbers, with and without a check digit, and cross-checks their airline
+// numeric code against an operating carrier.
+func TestParseTicketNumber(t *testing.T) {
+
+	tests := []struct {
+		raw     string
+		carrier string
+		// Whether an error is expected.
+		err bool
+		// The expected ticket number, check digit validity ("" if there is no check digit) and carrier match.
+		number string
+		valid  string
+		match  string
+	}{
+		{"0162305123456", "UA", false, "0162305123456", "", CARRIER_MATCH},
+		{"016-2305123456-6", "UA", false, "0162305123456", "true", CARRIER_MATCH},
+		{"016 2305123456 5", "UA", false, "0162305123456", "false", CARRIER_MATCH},
+		{"0141234567890", "LH", false, "0141234567890", "", CARRIER_MISMATCH},
+		{"0141234567890", "ZZ", false, "0141234567890", "", CARRIER_UNKNOWN},
+		{"014123456789", "AC", true, "", "", ""},
+		{"014ABCDEFGHIJ", "AC", true, "", "", ""},
+	}
+
+	for _, tt := range tests {
+
+		ticket, err := ParseTicketNumber(tt.raw)
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("'%s': expected an error", tt.raw)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("'%s': failed to parse, %v", tt.raw, err)
+			continue
+		}
+
+		if ticket.Number != tt.number {
+			t.Errorf("'%s': expected ticket number %s, got %s", tt.raw, tt.number, ticket.Number)
+		}
+
+		valid := ""
+
+		if ticket.CheckDigitValid != nil {
+			valid = strconv.FormatBool(*ticket.CheckDigitValid)
+		}
+
+		if valid != tt.valid {
+			t.Errorf("'%s': expected check digit validity '%s', got '%s'", tt.raw, tt.valid, valid)
+		}
+
+		ticket.CheckCarrier(tt.carrier)
+
+		if ticket.CarrierMatch != tt.match {
+			t.Errorf("'%s': expected carrier %s to %s, got %s", tt.raw, tt.carrier, tt.match, ticket.CarrierMatch)
+		}
+	}
+}
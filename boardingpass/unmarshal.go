@@ -0,0 +1,642 @@
+package boardingpass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sfomuseum/go-bcbp"
+)
+
+// STRATEGY_FIELD_SIZE is the strategy used to locate legs that have been concatenated together, using the
+// "field size of variable size field" of each leg to determine where the next leg begins.
+const STRATEGY_FIELD_SIZE string = "field_size"
+
+// STRATEGY_GROUP_SEPARATOR is the strategy used to locate legs that have been separated by the ASCII
+// group separator (0x1D) character.
+const STRATEGY_GROUP_SEPARATOR string = "group_separator"
+
+// Leg is a `bcbp.Leg` instance along with its decoded conditional section.
+type Leg struct {
+	*bcbp.Leg
+	// The decoded conditional section of the leg.
+	Conditional *Conditional `json:"conditional,omitempty"`
+	// The offset of the first character of the leg in the BCBP string.
+	Offset int `json:"offset"`
+	// The location of each mandatory field and conditional item in the leg, if requested.
+	FieldMap []*FieldLocation `json:"field_map,omitempty"`
+	// The exact characters of the leg, if parsed in lossless mode.
+	Raw *RawLeg `json:"raw,omitempty"`
+}
+
+// BCBP is a parsed BCBP string along with details about how it was parsed.
+type BCBP struct {
+	Legs []*Leg `json:"legs"`
+	// The decoded security data section, if present.
+	Security *Security `json:"security,omitempty"`
+	// The strategy used to locate individual legs in the BCBP string.
+	Strategy string `json:"strategy"`
+	// The version number declared by the first leg, or 0 if it has no conditional items.
+	Version int `json:"version,omitempty"`
+	// Any problems that were repaired while parsing the BCBP string in lenient mode, along with those which are
+	// reported in both modes: conditional items which are not defined by its version, conditional sections and
+	// security data which can not be decoded, and trailing data.
+	Warnings []*Warning `json:"warnings,omitempty"`
+	// The characters following the last leg and security data section, if parsed in lossless mode.
+	Trailing string `json:"trailing,omitempty"`
+}
+
+// UnmarshalOptions defines options for parsing BCBP strings.
+type UnmarshalOptions struct {
+	// Lenient enables the repair of common problems (incorrect leg counts, truncated passenger names or
+	// mandatory sections, invalid field sizes) which are recorded as warnings rather than returned as errors.
+	Lenient bool
+	// FieldMap enables recording the offset, length, raw characters and decoded value of every field.
+	FieldMap bool
+	// Lossless enables recording the exact characters of every field, and any characters between or after legs,
+	// such that `Marshal` will reproduce the BCBP string byte for byte.
+	Lossless bool
+}
+
+// Unmarshal parses 'raw' as a BCBP string. Unlike `bcbp.Unmarshal` legs are expected to be concatenated
+// together, as described by IATA Resolution 792, with the boundary of each leg derived from its "field size
+// of variable size field". Strings whose legs are separated by the ASCII group separator character are
+// also accepted.
+func Unmarshal(raw string) (*BCBP, error) {
+	opts := &UnmarshalOptions{}
+	return UnmarshalWithOptions(raw, opts)
+}
+
+// UnmarshalWithOptions parses 'raw' as a BCBP string using 'opts'. Any panics triggered while parsing
+// 'raw' are recovered and returned as errors.
+func UnmarshalWithOptions(raw string, opts *UnmarshalOptions) (b *BCBP, err error) {
+
+	defer func() {
+
+		r := recover()
+
+		if r != nil {
+			b = nil
+			err = newError(fmt.Errorf("Failed to parse BCBP string, %v", r), ERROR_INTERNAL, raw)
+		}
+	}()
+
+	if opts == nil {
+		opts = &UnmarshalOptions{}
+	}
+
+	d := &decoder{
+		lenient:   opts.Lenient,
+		field_map: opts.FieldMap,
+		lossless:  opts.Lossless,
+	}
+
+	err = d.validateLength(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err = d.unmarshal(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Lossless {
+
+		err = verifyLossless(b, raw)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// validateLength ensures that 'raw' is long enough to be parsed.
+func (d *decoder) validateLength(raw string) error {
+
+	if raw == "" {
+		return newError(fmt.Errorf("BCBP string is empty"), ERROR_EMPTY_INPUT, raw)
+	}
+
+	if !d.lenient && len(raw) < bcbp.OPTIONAL_DATA_OFFSET {
+		err := fmt.Errorf("BCBP string must be at least %d characters, got %d", bcbp.OPTIONAL_DATA_OFFSET, len(raw))
+		f := mandatoryField(len(raw))
+		return d.fail(err, ERROR_INPUT_TOO_SHORT, f.Name, f.Offset, raw[f.Offset:])
+	}
+
+	return nil
+}
+
+func (d *decoder) unmarshal(raw string) (*BCBP, error) {
+
+	var spans []*legSpan
+	var err error
+
+	strategy := STRATEGY_FIELD_SIZE
+
+	if strings.ContainsRune(raw, bcbp.GROUP_SEPARATOR) {
+		strategy = STRATEGY_GROUP_SEPARATOR
+		spans, err = d.separateLegs(raw)
+	} else {
+		spans, err = d.walkLegs(raw)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BCBP{
+		Legs:     make([]*Leg, len(spans)),
+		Strategy: strategy,
+	}
+
+	// The offset of the character following the previous leg
+	end := 0
+
+	for idx, s := range spans {
+
+		d.leg = idx
+
+		l, err := bcbp.ParseLeg(s.raw)
+
+		if err != nil {
+			err = fmt.Errorf("Failed to parse leg at offset %d (%s), %w", idx, s.raw, err)
+			return nil, d.fail(err, ERROR_FIELD_INVALID, "", s.offset, s.raw)
+		}
+
+		d.locations = nil
+		d.locateMandatory(raw, s, l)
+
+		variable := l.OptionalData[0:s.size]
+		base := s.position(bcbp.OPTIONAL_DATA_OFFSET)
+
+		// Any characters between the variable size field and the security data section
+		unused := ""
+
+		// Security data follows the variable size field of the last leg
+
+		if idx == len(spans)-1 {
+
+			rest := l.OptionalData[s.size:]
+			v, sec, err := d.splitSecurity(variable, rest, base)
+
+			if err != nil {
+				d.skip(err, WARNING_SECURITY_INVALID, "security_data", base+len(variable), rest)
+			} else {
+				variable = v
+				b.Security = sec
+			}
+
+			// The optional data of the leg, as returned by bcbp.ParseLeg, includes the security data section
+
+			if b.Security != nil {
+
+				if strings.HasSuffix(rest, b.Security.Raw) {
+					unused = strings.TrimSuffix(rest, b.Security.Raw)
+				}
+
+				l.OptionalData = strings.TrimSuffix(l.OptionalData, b.Security.Raw)
+			}
+		}
+
+		c, err := d.parseConditional(variable, base, idx == 0)
+
+		if err != nil {
+			d.skip(fmt.Errorf("Failed to parse conditional section of leg at offset %d, %w", idx, err), WARNING_CONDITIONAL_INVALID, "optional_data", base, variable)
+		}
+
+		b.Legs[idx] = &Leg{
+			Leg:         l,
+			Conditional: c,
+			Offset:      s.offset,
+			FieldMap:    d.locations,
+		}
+
+		if d.lossless {
+
+			b.Legs[idx].Raw, end = newRawLeg(raw, s, end, base, variable)
+
+			if c != nil {
+				b.Legs[idx].Raw.conditional = d.copyConditional(variable, idx == 0)
+			}
+
+			if b.Security != nil && idx == len(spans)-1 {
+				b.Legs[idx].Raw.Unused = unused
+				end = min(end+len(unused)+len(b.Security.Raw), len(raw))
+			}
+		}
+	}
+
+	if d.lossless {
+		b.Trailing = raw[end:]
+	}
+
+	b.Version = d.version
+	b.Warnings = d.warnings
+	return b, nil
+}
+
+// legSpan is the portion of a BCBP string belonging to a single leg.
+type legSpan struct {
+	// The leg, in the form expected by bcbp.ParseLeg.
+	raw string
+	// The offset of the leg in the BCBP string.
+	offset int
+	// The number of characters at the start of 'raw' that were copied from the first leg.
+	prefix int
+	// The position in 'raw' at which space characters were inserted to realign a truncated passenger name.
+	inserted_at int
+	// The number of space characters inserted to realign a truncated passenger name.
+	inserted int
+	// The length of the variable size field.
+	size int
+}
+
+// position returns the offset in the BCBP string of the character at 'idx' in 's.raw'.
+func (s *legSpan) position(idx int) int {
+
+	if s.inserted > 0 && idx >= s.inserted_at {
+		idx = max(idx-s.inserted, s.inserted_at)
+	}
+
+	return s.offset + idx - s.prefix
+}
+
+// walkLegs locates the legs in 'raw' using the "field size of variable size field" of each leg.
+func (d *decoder) walkLegs(raw string) ([]*legSpan, error) {
+
+	s, count, err := d.firstLeg(raw, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := s.raw[0:bcbp.OPERATING_CARRIER_PNR_OFFSET]
+	spans := make([]*legSpan, 0)
+
+	for {
+
+		idx := len(spans)
+		d.leg = idx
+
+		err := d.variableSize(s)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid leg at offset %d, %w", idx, err)
+		}
+
+		end := bcbp.OPTIONAL_DATA_OFFSET + s.size
+		next := min(s.position(end), len(raw))
+		rest := raw[next:]
+
+		// Security data is never the start of another leg
+		more := idx < count-1 && !strings.HasPrefix(rest, BEGINNING_OF_SECURITY_DATA_MARKER)
+
+		if !more && d.lenient && looksLikeLeg(prefix, rest) {
+			more = true
+		}
+
+		if !more || rest == "" {
+			// The last leg is assigned any remaining data, namely the security data section
+			spans = append(spans, s)
+			break
+		}
+
+		s.raw = s.raw[0:end]
+		spans = append(spans, s)
+
+		d.leg = idx + 1
+		s, err = d.nextLeg(prefix, rest, next)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid leg at offset %d, %w", idx+1, err)
+		}
+	}
+
+	err = d.checkLegCount(count, len(spans))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return spans, nil
+}
+
+// separateLegs locates the legs in 'raw' by splitting it on the ASCII group separator character.
+func (d *decoder) separateLegs(raw string) ([]*legSpan, error) {
+
+	parts := strings.Split(raw, string(bcbp.GROUP_SEPARATOR))
+
+	spans := make([]*legSpan, 0)
+	count := 0
+
+	var prefix string
+	offset := 0
+
+	for _, part := range parts {
+
+		part_offset := offset
+		offset += len(part) + 1
+
+		if strings.TrimSpace(part) == "" {
+
+			if d.lenient {
+				d.warn(WARNING_EMPTY_LEG, "", part_offset, part, "Skipping empty leg")
+			}
+
+			continue
+		}
+
+		var s *legSpan
+		var err error
+
+		idx := len(spans)
+
+		// Security data is never a leg, even if it is separated from the last leg, so the last leg is assigned the
+		// separator and the remaining data
+
+		if idx > 0 && strings.HasPrefix(part, BEGINNING_OF_SECURITY_DATA_MARKER) {
+			spans[idx-1].raw += raw[part_offset-1:]
+			break
+		}
+
+		d.leg = idx
+
+		switch {
+		case idx == 0:
+
+			s, count, err = d.firstLeg(part, part_offset)
+
+			if err == nil {
+				prefix = s.raw[0:bcbp.OPERATING_CARRIER_PNR_OFFSET]
+			}
+
+		case strings.HasPrefix(part, prefix[0:bcbp.PASSENGER_NAME_OFFSET]) && len(part) >= bcbp.OPTIONAL_DATA_OFFSET:
+
+			// Legs which repeat the format code, number of legs, passenger name and electronic ticket indicator
+
+			s = &legSpan{
+				raw:    part,
+				offset: part_offset,
+			}
+
+		default:
+			s, err = d.nextLeg(prefix, part, part_offset)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid leg at offset %d, %w", idx, err)
+		}
+
+		err = d.variableSize(s)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid leg at offset %d, %w", idx, err)
+		}
+
+		spans = append(spans, s)
+	}
+
+	if len(spans) == 0 {
+		return nil, newError(fmt.Errorf("BCBP string does not contain any legs"), ERROR_EMPTY_INPUT, raw)
+	}
+
+	err := d.checkLegCount(count, len(spans))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return spans, nil
+}
+
+// firstLeg returns the first leg in 'raw', which starts at 'offset', along with the number of legs encoded. If 'd' is
+// lenient and the number of legs encoded is invalid -1 is returned.
+func (d *decoder) firstLeg(raw string, offset int) (*legSpan, int, error) {
+
+	if raw == "" {
+		return nil, -1, newError(fmt.Errorf("BCBP string is empty"), ERROR_EMPTY_INPUT, raw)
+	}
+
+	s := &legSpan{
+		raw:    raw,
+		offset: offset,
+	}
+
+	if d.lenient {
+		d.realignPassengerName(s)
+	}
+
+	err := d.padMandatory(s)
+
+	if err != nil {
+		return nil, -1, err
+	}
+
+	format_code := s.raw[bcbp.FORMAT_CODE_OFFSET : bcbp.FORMAT_CODE_OFFSET+bcbp.FORMAT_CODE]
+
+	if format_code != "M" {
+
+		err := d.recover(fmt.Errorf("BCBP string must start with M"), WARNING_BAD_FORMAT_CODE, "format_code", s.position(bcbp.FORMAT_CODE_OFFSET), format_code)
+
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	str_count := s.raw[bcbp.NUMBER_OF_LEGS_OFFSET : bcbp.NUMBER_OF_LEGS_OFFSET+bcbp.NUMBER_OF_LEGS]
+	count, err := strconv.Atoi(str_count)
+
+	if err != nil || count < 1 {
+
+		err := d.recover(fmt.Errorf("Invalid M (leg) count '%s'", str_count), WARNING_LEG_COUNT_INVALID, "number_of_legs", s.position(bcbp.NUMBER_OF_LEGS_OFFSET), str_count)
+
+		if err != nil {
+			return nil, -1, err
+		}
+
+		count = -1
+	}
+
+	return s, count, nil
+}
+
+// nextLeg returns a subsequent leg in 'raw', which starts at 'offset', prepending 'prefix' so that it can be parsed by bcbp.ParseLeg.
+func (d *decoder) nextLeg(prefix string, raw string, offset int) (*legSpan, error) {
+
+	s := &legSpan{
+		raw:    prefix + raw,
+		offset: offset,
+		prefix: len(prefix),
+	}
+
+	err := d.padMandatory(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// padMandatory ensures that 's' contains all the mandatory characters of a leg, padding it with spaces if 'd' is lenient.
+func (d *decoder) padMandatory(s *legSpan) error {
+
+	missing := bcbp.OPTIONAL_DATA_OFFSET - len(s.raw)
+
+	if missing <= 0 {
+		return nil
+	}
+
+	f := mandatoryField(len(s.raw))
+	err := fmt.Errorf("Leg is %d characters shorter than the %d mandatory characters", missing, bcbp.OPTIONAL_DATA_OFFSET-s.prefix)
+
+	// Only the characters of the truncated field are reported, the fields which follow it are missing entirely
+
+	start := max(f.Offset, s.prefix)
+
+	err = d.recover(err, WARNING_MANDATORY_SECTION_TRUNCATED, f.Name, s.position(start), s.raw[start:])
+
+	if err != nil {
+		return err
+	}
+
+	s.raw = s.raw + strings.Repeat(" ", missing)
+	return nil
+}
+
+// variableSize assigns the length of the variable size field of 's'.
+func (d *decoder) variableSize(s *legSpan) error {
+
+	sz_raw := s.raw[bcbp.OPTIONAL_DATA_SIZE_OFFSET:bcbp.OPTIONAL_DATA_OFFSET]
+	offset := s.position(bcbp.OPTIONAL_DATA_SIZE_OFFSET)
+
+	optional := s.raw[bcbp.OPTIONAL_DATA_OFFSET:]
+
+	sz, err := ParseFieldSize(sz_raw)
+
+	if err != nil {
+
+		err = d.recover(fmt.Errorf("Failed to parse field size of variable size field, %w", err), WARNING_FIELD_SIZE_INVALID, "optional_data_size", offset, sz_raw)
+
+		if err != nil {
+			return err
+		}
+
+		// Assume that everything up to the security data belongs to the variable size field
+
+		sz = strings.Index(optional, BEGINNING_OF_SECURITY_DATA_MARKER)
+
+		if sz == -1 {
+			sz = len(optional)
+		}
+	}
+
+	if sz > len(optional) {
+
+		err := d.recover(fmt.Errorf("Field size of variable size field (%d) exceeds remaining data (%d)", sz, len(optional)), WARNING_FIELD_SIZE_MISMATCH, "optional_data_size", offset, sz_raw)
+
+		if err != nil {
+			return err
+		}
+
+		sz = len(optional)
+	}
+
+	s.size = sz
+	return nil
+}
+
+// realignPassengerName inserts spaces after a passenger name that is shorter than 20 characters, if doing so
+// realigns the fields that follow it.
+func (d *decoder) realignPassengerName(s *legSpan) {
+
+	if len(s.raw) < bcbp.COMPARTMENT_CODE_OFFSET || isAligned(s.raw) {
+		return
+	}
+
+	name_end := bcbp.PASSENGER_NAME_OFFSET + bcbp.PASSENGER_NAME
+
+	for k := 1; k < bcbp.PASSENGER_NAME; k++ {
+
+		at := name_end - k
+		candidate := s.raw[0:at] + strings.Repeat(" ", k) + s.raw[at:]
+
+		if !isAligned(candidate) {
+			continue
+		}
+
+		s.raw = candidate
+		s.inserted_at = at
+		s.inserted = k
+
+		name := s.raw[bcbp.PASSENGER_NAME_OFFSET:at]
+		d.warn(WARNING_PASSENGER_NAME_TRUNCATED, "passenger_name", s.offset+at, name, "Passenger name is %d characters short, padding with spaces", k)
+		return
+	}
+}
+
+// checkLegCount ensures that the number of legs encoded, 'count', matches the number of legs found.
+func (d *decoder) checkLegCount(count int, found int) error {
+
+	if count == -1 || count == found {
+		return nil
+	}
+
+	// The number of legs is encoded in the first leg
+	d.leg = 0
+
+	err := fmt.Errorf("M (leg) count mismatch, expected %d legs but found %d", count, found)
+	return d.recover(err, WARNING_LEG_COUNT_MISMATCH, "number_of_legs", bcbp.NUMBER_OF_LEGS_OFFSET, strconv.Itoa(count))
+}
+
+// skip records 'err' as a warning, in both strict and lenient mode. It is used for problems with the optional parts
+// of a BCBP string which are omitted, rather than treated as fatal, in both modes.
+func (d *decoder) skip(err error, code string, field string, offset int, raw string) {
+	d.warn(code, field, offset, raw, "%v", err)
+}
+
+// looksLikeLeg returns true if 'raw' plausibly contains the mandatory items of a subsequent leg.
+func looksLikeLeg(prefix string, raw string) bool {
+
+	if len(raw) < REPEATED_MANDATORY || strings.HasPrefix(raw, BEGINNING_OF_SECURITY_DATA_MARKER) {
+		return false
+	}
+
+	candidate := prefix + raw
+
+	_, err := ParseFieldSize(candidate[bcbp.OPTIONAL_DATA_SIZE_OFFSET:bcbp.OPTIONAL_DATA_OFFSET])
+
+	return err == nil && isAligned(candidate)
+}
+
+// isAligned returns true if the airport codes and date of flight in 'raw' are where they are expected to be.
+func isAligned(raw string) bool {
+
+	if len(raw) < bcbp.COMPARTMENT_CODE_OFFSET {
+		return false
+	}
+
+	from := raw[bcbp.DEPARTURE_AIRPORT_OFFSET : bcbp.DEPARTURE_AIRPORT_OFFSET+bcbp.DEPARTURE_AIRPORT]
+	to := raw[bcbp.ARRIVAL_AIRPORT_OFFSET : bcbp.ARRIVAL_AIRPORT_OFFSET+bcbp.ARRIVAL_AIRPORT]
+	date := raw[bcbp.FLIGHT_DATE_OFFSET : bcbp.FLIGHT_DATE_OFFSET+bcbp.FLIGHT_DATE]
+
+	return isClass(from, unicode.IsLetter) && isClass(to, unicode.IsLetter) && isClass(date, unicode.IsDigit)
+}
+
+func isClass(raw string, class_func func(rune) bool) bool {
+
+	for _, r := range raw {
+
+		if !class_func(r) {
+			return false
+		}
+	}
+
+	return raw != ""
+}
@@ -0,0 +1,153 @@
+package boardingpass
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestUnmarshalPrefixes parses every prefix, from 0 to 60 characters, of a BCBP string in strict and lenient mode.
+// In strict mode every prefix shorter than the mandatory items must be rejected with an `Error`. In lenient mode a
+// prefix may instead be padded, and reported with a warning. Neither mode may trigger an unexpected error (a panic).
+func TestUnmarshalPrefixes(t *testing.T) {
+
+	bcbp_str := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 326J001A0025 100"
+
+	tests := []struct {
+		name string
+		opts *UnmarshalOptions
+	}{
+		{"strict", &UnmarshalOptions{}},
+		{"strict_field_map", &UnmarshalOptions{FieldMap: true, Lossless: true}},
+		{"lenient", &UnmarshalOptions{Lenient: true}},
+		{"lenient_field_map", &UnmarshalOptions{Lenient: true, FieldMap: true, Lossless: true}},
+	}
+
+	for _, tt := range tests {
+
+		for length := 0; length <= len(bcbp_str); length++ {
+
+			raw := bcbp_str[:length]
+			truncated := length < len(bcbp_str)
+
+			b, err := UnmarshalWithOptions(raw, tt.opts)
+
+			if err == nil {
+
+				if truncated && !tt.opts.Lenient {
+					t.Errorf("%s: length %d: expected an error", tt.name, length)
+				}
+
+				if truncated && tt.opts.Lenient && !hasWarning(b, WARNING_MANDATORY_SECTION_TRUNCATED) {
+					t.Errorf("%s: length %d: expected a %s warning", tt.name, length, WARNING_MANDATORY_SECTION_TRUNCATED)
+				}
+
+				continue
+			}
+
+			var bp_err *Error
+
+			if !errors.As(err, &bp_err) {
+				t.Errorf("%s: length %d: expected *Error, got %T (%v)", tt.name, length, err, err)
+				continue
+			}
+
+			if bp_err.Code == ERROR_INTERNAL {
+				t.Errorf("%s: length %d: unexpected internal error, %v", tt.name, length, err)
+			}
+
+			if !truncated {
+				t.Errorf("%s: length %d: unexpected error, %v", tt.name, length, err)
+			}
+		}
+	}
+}
+
+// TestUnmarshalLegs parses multi-leg BCBP strings, whose legs are either concatenated or separated by the ASCII group
+// separator character, and ensures that each leg is located using its "field size of variable size field".
+func TestUnmarshalLegs(t *testing.T) {
+
+	leg_1 := "M3DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z"
+	leg_2 := "DEF456 FRAGVALH 3664 227C012C0002 12E2A0140987654321 1AC AC 1234567890123    2PCNWQ"
+	leg_3 := "GHI789 GVALHRBA 0001 228Y003B0003 100"
+
+	tests := []struct {
+		name    string
+		raw     string
+		lenient bool
+		// Whether an error is expected.
+		err      bool
+		strategy string
+		// The expected operating carrier PNR code of each leg.
+		pnrs []string
+		// The expected warning, or "".
+		warning string
+	}{
+		{"concatenated", leg_1 + leg_2 + leg_3, false, false, STRATEGY_FIELD_SIZE, []string{"ABC123", "DEF456", "GHI789"}, ""},
+		{"security_data", leg_1 + leg_2 + leg_3 + "^108ABCDEFGH", false, false, STRATEGY_FIELD_SIZE, []string{"ABC123", "DEF456", "GHI789"}, ""},
+		{"group_separator", leg_1 + "\x1dM3DESMARAIS/LUC       E" + leg_2 + "\x1dM3DESMARAIS/LUC       E" + leg_3, false, false, STRATEGY_GROUP_SEPARATOR, []string{"ABC123", "DEF456", "GHI789"}, ""},
+		{"too_few_legs_strict", leg_1 + leg_2, false, true, "", nil, ""},
+		{"too_few_legs_lenient", leg_1 + leg_2, true, false, STRATEGY_FIELD_SIZE, []string{"ABC123", "DEF456"}, WARNING_LEG_COUNT_MISMATCH},
+		{"too_many_legs_lenient", "M2" + leg_1[2:] + leg_2 + leg_3, true, false, STRATEGY_FIELD_SIZE, []string{"ABC123", "DEF456", "GHI789"}, WARNING_LEG_COUNT_MISMATCH},
+		{"truncated_leg_strict", leg_1 + leg_2 + leg_3[0:20], false, true, "", nil, ""},
+	}
+
+	for _, tt := range tests {
+
+		b, err := UnmarshalWithOptions(tt.raw, &UnmarshalOptions{Lenient: tt.lenient})
+
+		if tt.err {
+
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: failed to parse, %v", tt.name, err)
+			continue
+		}
+
+		if b.Strategy != tt.strategy {
+			t.Errorf("%s: expected strategy %s, got %s", tt.name, tt.strategy, b.Strategy)
+		}
+
+		if len(b.Legs) != len(tt.pnrs) {
+			t.Errorf("%s: expected %d legs, got %d", tt.name, len(tt.pnrs), len(b.Legs))
+			continue
+		}
+
+		for idx, l := range b.Legs {
+
+			if l.OperatingCarrierPNR != tt.pnrs[idx] {
+				t.Errorf("%s: expected leg %d to have PNR code '%s', got '%s'", tt.name, idx, tt.pnrs[idx], l.OperatingCarrierPNR)
+			}
+
+			if l.PassengerName != "DESMARAIS/LUC" {
+				t.Errorf("%s: expected leg %d to have passenger name 'DESMARAIS/LUC', got '%s'", tt.name, idx, l.PassengerName)
+			}
+		}
+
+		if tt.warning == "" && len(b.Warnings) > 0 {
+			t.Errorf("%s: unexpected warning %s", tt.name, b.Warnings[0].Code)
+		}
+
+		if tt.warning != "" && !hasWarning(b, tt.warning) {
+			t.Errorf("%s: expected a %s warning", tt.name, tt.warning)
+		}
+	}
+}
+
+// hasWarning reports whether 'b' has a warning whose code is 'code'.
+func hasWarning(b *BCBP, code string) bool {
+
+	for _, w := range b.Warnings {
+
+		if w.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,550 @@
+package boardingpass
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SEVERITY_ERROR is assigned to findings which violate IATA Resolution 792.
+const SEVERITY_ERROR string = "error"
+
+// SEVERITY_WARNING is assigned to findings which most readers tolerate but which do not follow Resolution 792.
+const SEVERITY_WARNING string = "warning"
+
+// SEVERITY_INFO is assigned to findings which are permitted by Resolution 792 but may be of interest.
+const SEVERITY_INFO string = "info"
+
+// Findings for problems which would be repaired in lenient mode use the same code as the corresponding warning.
+// The following codes are only used for findings.
+
+// FINDING_CHARACTER_CLASS is reported when a field contains characters that are not permitted for that field.
+const FINDING_CHARACTER_CLASS string = "CHARACTER_CLASS"
+
+// FINDING_DATE_OUT_OF_RANGE is reported when a Julian date is not between 001 and 366.
+const FINDING_DATE_OUT_OF_RANGE string = "DATE_OUT_OF_RANGE"
+
+// FINDING_UNKNOWN_CODE is reported when a coded field contains a value that is not defined by Resolution 792.
+const FINDING_UNKNOWN_CODE string = "UNKNOWN_CODE"
+
+// FINDING_LEG_COUNT_UNSUPPORTED is reported when the number of legs is not between 1 and MAX_LEGS.
+const FINDING_LEG_COUNT_UNSUPPORTED string = "LEG_COUNT_UNSUPPORTED"
+
+// FINDING_VERSION_UNSUPPORTED is reported when the version number is not between MIN_VERSION and MAX_VERSION.
+const FINDING_VERSION_UNSUPPORTED string = "VERSION_UNSUPPORTED"
+
+// FINDING_ITEM_MISSING is reported when a conditional item defined by the version of a BCBP string is absent.
+const FINDING_ITEM_MISSING string = "ITEM_MISSING"
+
+// FINDING_SECURITY_DATA_MISSING is reported when a BCBP string has no security data section.
+const FINDING_SECURITY_DATA_MISSING string = "SECURITY_DATA_MISSING"
+
+// Finding describes a way in which a BCBP string does not conform to IATA Resolution 792.
+type Finding struct {
+	// The severity of the finding. One of the SEVERITY_ constants.
+	Severity string `json:"severity"`
+	// A machine-readable code for the finding. One of the FINDING_, WARNING_ or ERROR_ constants.
+	Code string `json:"code"`
+	// The name of the field, or conditional item, where the problem was found.
+	Field string `json:"field"`
+	// The offset of the problem in the BCBP string or -1 if the problem is not specific to a location.
+	Offset int `json:"offset"`
+	// The index of the leg where the problem was found or -1 if the problem is not specific to a leg.
+	LegIndex int `json:"leg_index"`
+	// The raw characters where the problem was found.
+	Raw string `json:"raw"`
+	// A human-readable description of the problem.
+	Message string `json:"message"`
+}
+
+// Report is the result of checking a BCBP string against IATA Resolution 792.
+type Report struct {
+	// Whether or not the BCBP string conforms to Resolution 792, meaning that there are no findings with
+	// SEVERITY_ERROR.
+	Valid bool `json:"valid"`
+	// The version number declared in the conditional section of the first leg, if present.
+	Version int `json:"version,omitempty"`
+	// The number of legs found.
+	Legs int `json:"legs"`
+	// The number of findings with SEVERITY_ERROR.
+	Errors int `json:"errors"`
+	// The number of findings with SEVERITY_WARNING.
+	Warnings int `json:"warnings"`
+	// The findings, ordered by offset.
+	Findings []*Finding `json:"findings"`
+}
+
+// fieldRule describes the characters permitted in a field.
+type fieldRule struct {
+	// The pattern a conforming field must match.
+	strict *regexp.Regexp
+	// The pattern a field not matching 'strict' may match and be reported with SEVERITY_WARNING rather than
+	// SEVERITY_ERROR. This may be nil.
+	loose *regexp.Regexp
+	// A description of the characters permitted by 'strict'.
+	description string
+	// The codes defined for the field, if it is a coded field.
+	codes []string
+	// The severity of a finding for a value not in 'codes'.
+	unknown string
+	// An additional check of the value of a field matching 'strict' or 'loose', returning a finding code and
+	// error if there is a problem.
+	check func(raw string) (string, error)
+}
+
+var fieldRules = map[string]*fieldRule{
+	// Mandatory items
+	"format_code": {
+		strict:      regexp.MustCompile(`^M$`),
+		description: `"M"`,
+	},
+	"number_of_legs": {
+		strict:      regexp.MustCompile(`^[0-9]$`),
+		description: "a single digit",
+		check:       checkLegCount,
+	},
+	"passenger_name": {
+		strict:      regexp.MustCompile(`^[A-Z/ .'\-]{20}$`),
+		loose:       regexp.MustCompile(`^[A-Za-z0-9/ .'\-]{20}$`),
+		description: `upper case letters, "/", spaces, hyphens, periods or apostrophes`,
+	},
+	"electronic_ticket_indicator": {
+		strict:      regexp.MustCompile(`^[E ]$`),
+		description: `"E" or a space`,
+	},
+	"operating_carrier_pnr": {
+		strict:      regexp.MustCompile(`^[A-Z0-9]{1,7} *$`),
+		loose:       regexp.MustCompile(`^ *[A-Za-z0-9]{1,7} *$`),
+		description: "a left-justified alphanumeric code",
+	},
+	"from_airport": {
+		strict:      regexp.MustCompile(`^[A-Z]{3}$`),
+		description: "three upper case letters",
+	},
+	"to_airport": {
+		strict:      regexp.MustCompile(`^[A-Z]{3}$`),
+		description: "three upper case letters",
+	},
+	"operating_carrier_designator": {
+		strict:      regexp.MustCompile(`^[A-Z0-9]{2}[A-Z0-9 ]$`),
+		loose:       regexp.MustCompile(`^ ?[A-Z0-9]{2,3} ?$`),
+		description: "a left-justified two or three character airline designator",
+	},
+	"flight_number": {
+		strict:      regexp.MustCompile(`^[0-9]{4}[A-Z ]$`),
+		loose:       regexp.MustCompile(`^ *[0-9]{1,4}[A-Z ]? *$`),
+		description: "four digits, with leading zeros, and an optional letter",
+	},
+	"date_of_flight": {
+		strict:      regexp.MustCompile(`^[0-9]{3}$`),
+		loose:       regexp.MustCompile(`^ *[0-9]{1,3}$`),
+		description: "a three digit Julian date",
+		check:       checkDayOfYear,
+	},
+	"compartment_code": {
+		strict:      regexp.MustCompile(`^[A-Z]$`),
+		description: "an upper case letter",
+	},
+	"seat_number": {
+		strict:      regexp.MustCompile(`^[0-9]{3}[A-Z]$`),
+		loose:       regexp.MustCompile(`^(?: *[0-9]{1,3}[A-Z]|[A-Z ]{4}|[0-9]{3} )$`),
+		description: "three digits, with leading zeros, and a letter",
+	},
+	"checkin_sequence_number": {
+		strict:      regexp.MustCompile(`^[0-9]{4}[A-Z ]$`),
+		loose:       regexp.MustCompile(`^[A-Z0-9 ]{5}$`),
+		description: "four digits, with leading zeros, and an optional letter",
+	},
+	"passenger_status": {
+		strict:      regexp.MustCompile(`^[0-9A-Z]$`),
+		description: "a digit or upper case letter",
+		codes:       codeValues("passenger_status"),
+		unknown:     SEVERITY_ERROR,
+	},
+	"optional_data_size": hexRule,
+	// Conditional items
+	"version_number": {
+		strict:      regexp.MustCompile(`^[0-9]$`),
+		description: "a single digit",
+		check:       checkVersion,
+	},
+	"unique_field_size":   hexRule,
+	"repeated_field_size": hexRule,
+	"passenger_description": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("passenger_description"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"source_of_checkin": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("source_of_checkin"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"source_of_boarding_pass_issuance": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("source_of_boarding_pass_issuance"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"date_of_issue": {
+		strict:      regexp.MustCompile(`^(?:[0-9]{4}| {4})$`),
+		description: "a year digit and three digit Julian date, or spaces",
+		check:       checkDateOfIssue,
+	},
+	"document_type": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("document_type"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"airline_designator_of_issuer":       designatorRule,
+	"baggage_tag_license_plate":          baggageTagRule,
+	"first_non_consecutive_baggage_tag":  baggageTagRule,
+	"second_non_consecutive_baggage_tag": baggageTagRule,
+	"airline_numeric_code": {
+		strict:      regexp.MustCompile(`^(?:[0-9]{3}| {3})$`),
+		description: "three digits or spaces",
+	},
+	"document_serial_number": {
+		strict:      regexp.MustCompile(`^(?:[0-9]{10}| {10})$`),
+		loose:       regexp.MustCompile(`^[A-Z0-9 ]{10}$`),
+		description: "ten digits or spaces",
+	},
+	"selectee_indicator": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("selectee_indicator"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"international_documentation_verification": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("international_documentation_verification"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"marketing_carrier_designator":      designatorRule,
+	"frequent_flyer_airline_designator": designatorRule,
+	"frequent_flyer_number": {
+		strict:      regexp.MustCompile(`^[A-Z0-9 ]{16}$`),
+		description: "upper case letters, digits or spaces",
+	},
+	"id_ad_indicator": {
+		strict:      regexp.MustCompile(`^[0-9A-Z ]$`),
+		description: "a digit, upper case letter or space",
+		codes:       codeValues("id_ad_indicator"),
+		unknown:     SEVERITY_WARNING,
+	},
+	"free_baggage_allowance": {
+		strict:      regexp.MustCompile(`^(?:[0-9]{2}[KL]|[0-9]PC| {3})$`),
+		loose:       regexp.MustCompile(`^[A-Z0-9 ]{3}$`),
+		description: `a weight in kilos ("20K") or pounds ("40L"), a number of pieces ("2PC") or spaces`,
+	},
+	"fast_track": {
+		strict:      regexp.MustCompile(`^[YN ]$`),
+		description: `"Y", "N" or a space`,
+	},
+	// Security data
+	"type_of_security_data": {
+		strict:      regexp.MustCompile(`^[A-Z0-9]$`),
+		description: "an upper case letter or digit",
+	},
+	"length_of_security_data": hexRule,
+}
+
+var hexRule = &fieldRule{
+	strict:      regexp.MustCompile(`^[0-9A-F]{2}$`),
+	loose:       regexp.MustCompile(`^[0-9A-Fa-f]{2}$`),
+	description: "two upper case hexadecimal digits",
+}
+
+var designatorRule = &fieldRule{
+	strict:      regexp.MustCompile(`^(?:[A-Z0-9]{2}[A-Z0-9 ]| {3})$`),
+	loose:       regexp.MustCompile(`^[A-Z0-9 ]{3}$`),
+	description: "a left-justified two or three character airline designator or spaces",
+}
+
+var baggageTagRule = &fieldRule{
+	strict:      regexp.MustCompile(`^(?:[0-9]{13}| {13})$`),
+	description: "thirteen digits or spaces",
+}
+
+// Validate checks 'raw' against IATA Resolution 792 and returns a `Report` describing every problem found.
+// Unlike `Unmarshal` problems are not fatal: 'raw' is decoded in lenient mode and each repair is included in
+// the report alongside problems with the characters and values of individual fields.
+func Validate(raw string) *Report {
+
+	v := &validator{
+		flagged: make(map[string]bool),
+	}
+
+	opts := &UnmarshalOptions{
+		Lenient:  true,
+		FieldMap: true,
+	}
+
+	b, err := UnmarshalWithOptions(raw, opts)
+
+	if err != nil {
+
+		var e *Error
+
+		if !errors.As(err, &e) {
+			e = newError(err, ERROR_INTERNAL, raw)
+		}
+
+		v.add(SEVERITY_ERROR, e.Code, e.Field, e.Offset, e.LegIndex, e.Raw, "%v", err)
+		return v.report()
+	}
+
+	v.legs = len(b.Legs)
+
+	for _, w := range b.Warnings {
+		v.add(warningSeverity(w.Code), w.Code, w.Field, w.Offset, w.LegIndex, w.Raw, "%s", w.Message)
+	}
+
+	if len(b.Legs) > 0 && b.Legs[0].Conditional != nil && b.Legs[0].Conditional.VersionNumber != nil {
+		v.version = b.Legs[0].Conditional.VersionNumber.Value
+	}
+
+	for idx, l := range b.Legs {
+
+		for _, loc := range l.FieldMap {
+			v.checkField(idx, loc)
+		}
+
+		v.checkItems(idx, l)
+	}
+
+	if b.Security == nil {
+		v.add(SEVERITY_INFO, FINDING_SECURITY_DATA_MISSING, "", -1, -1, "", "BCBP string has no security data")
+	} else {
+
+		for _, loc := range b.Security.FieldMap {
+			v.checkField(len(b.Legs)-1, loc)
+		}
+	}
+
+	return v.report()
+}
+
+// validator accumulates the findings for a BCBP string.
+type validator struct {
+	findings []*Finding
+	// The version number declared by the first leg, or 0.
+	version int
+	legs    int
+	// The leg index and field of findings already reported, so that problems reported while decoding are not
+	// reported a second time.
+	flagged map[string]bool
+}
+
+func (v *validator) add(severity string, code string, field string, offset int, leg int, raw string, msg string, args ...any) {
+
+	f := &Finding{
+		Severity: severity,
+		Code:     code,
+		Field:    field,
+		Offset:   offset,
+		LegIndex: leg,
+		Raw:      raw,
+		Message:  fmt.Sprintf(msg, args...),
+	}
+
+	v.findings = append(v.findings, f)
+
+	if field != "" {
+		v.flagged[flaggedKey(leg, field)] = true
+	}
+}
+
+// checkField checks the characters and value of the field at 'loc' in the leg at index 'leg'.
+func (v *validator) checkField(leg int, loc *FieldLocation) {
+
+	rule, ok := fieldRules[loc.Field]
+
+	if !ok || v.flagged[flaggedKey(leg, loc.Field)] {
+		return
+	}
+
+	if !rule.strict.MatchString(loc.Raw) {
+
+		severity := SEVERITY_ERROR
+
+		if rule.loose != nil && rule.loose.MatchString(loc.Raw) {
+			severity = SEVERITY_WARNING
+		}
+
+		v.add(severity, FINDING_CHARACTER_CLASS, loc.Field, loc.Offset, leg, loc.Raw, "%s must be %s, got '%s'", loc.Field, rule.description, loc.Raw)
+
+		if severity == SEVERITY_ERROR {
+			return
+		}
+	}
+
+	if len(rule.codes) > 0 && !slices.Contains(rule.codes, loc.Raw) {
+		v.add(rule.unknown, FINDING_UNKNOWN_CODE, loc.Field, loc.Offset, leg, loc.Raw, "'%s' is not a known %s code", loc.Raw, loc.Field)
+		return
+	}
+
+	if rule.check != nil {
+
+		code, err := rule.check(loc.Raw)
+
+		if err != nil {
+			v.add(SEVERITY_ERROR, code, loc.Field, loc.Offset, leg, loc.Raw, "%v", err)
+		}
+	}
+}
+
+// checkItems checks that the conditional items of 'l', the leg at index 'idx', are those defined by the version
+// of the BCBP string.
+func (v *validator) checkItems(idx int, l *Leg) {
+
+	if v.version == 0 {
+		return
+	}
+
+	present := make(map[string]*FieldLocation)
+
+	for _, loc := range l.FieldMap {
+		present[loc.Field] = loc
+	}
+
+	if idx == 0 {
+
+		header, ok := present["unique_field_size"]
+
+		if !ok {
+			v.add(SEVERITY_ERROR, FINDING_ITEM_MISSING, "unique_field_size", -1, idx, "", "Version %d conditional section is missing the field size of unique items", v.version)
+		} else {
+			v.checkSection(idx, uniqueItems, present, header)
+		}
+	}
+
+	header, ok := present["repeated_field_size"]
+
+	if !ok {
+		v.add(SEVERITY_INFO, FINDING_ITEM_MISSING, "repeated_field_size", -1, idx, "", "Leg has no repeated conditional items")
+		return
+	}
+
+	v.checkSection(idx, repeatedItems, present, header)
+}
+
+// checkSection reports the 'items' defined by the version of the BCBP string which are not 'present', and those
+// which are present but not defined, in the section introduced by the field size at 'header'.
+func (v *validator) checkSection(idx int, items []*conditionalItem, present map[string]*FieldLocation, header *FieldLocation) {
+
+	for _, i := range items {
+
+		_, ok := present[i.Name]
+
+		// Items which are present but not defined are reported, using WARNING_ITEM_UNDEFINED, while decoding
+
+		if !ok && i.Version <= v.version {
+			// Items at the end of a section may be omitted by declaring a shorter field size
+			v.add(SEVERITY_INFO, FINDING_ITEM_MISSING, i.Name, header.Offset, idx, header.Raw, "%s is defined by version %d but is not present", i.Name, v.version)
+		}
+	}
+}
+
+func (v *validator) report() *Report {
+
+	sort.SliceStable(v.findings, func(i, j int) bool {
+		return v.findings[i].Offset < v.findings[j].Offset
+	})
+
+	r := &Report{
+		Version:  v.version,
+		Legs:     v.legs,
+		Findings: v.findings,
+	}
+
+	if r.Findings == nil {
+		r.Findings = make([]*Finding, 0)
+	}
+
+	for _, f := range r.Findings {
+
+		switch f.Severity {
+		case SEVERITY_ERROR:
+			r.Errors += 1
+		case SEVERITY_WARNING:
+			r.Warnings += 1
+		}
+	}
+
+	r.Valid = r.Errors == 0
+	return r
+}
+
+func flaggedKey(leg int, field string) string {
+	return fmt.Sprintf("%d:%s", leg, field)
+}
+
+// warningSeverity returns the severity of a finding for a problem repaired while decoding, identified by 'code'.
+func warningSeverity(code string) string {
+
+	switch code {
+	case WARNING_EMPTY_LEG, WARNING_TRAILING_DATA, WARNING_ITEM_UNDEFINED:
+		return SEVERITY_WARNING
+	default:
+		return SEVERITY_ERROR
+	}
+}
+
+// checkDayOfYear ensures that 'raw' is a Julian date between 001 and 366.
+func checkDayOfYear(raw string) (string, error) {
+
+	day, err := strconv.Atoi(strings.TrimSpace(raw))
+
+	if err != nil {
+		return FINDING_CHARACTER_CLASS, fmt.Errorf("Failed to parse Julian date '%s', %w", raw, err)
+	}
+
+	if day < 1 || day > 366 {
+		return FINDING_DATE_OUT_OF_RANGE, fmt.Errorf("Julian date must be between 001 and 366, got '%s'", raw)
+	}
+
+	return "", nil
+}
+
+// checkDateOfIssue ensures that the day of year in 'raw', a date of issue, is between 001 and 366.
+func checkDateOfIssue(raw string) (string, error) {
+
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+
+	return checkDayOfYear(raw[1:])
+}
+
+// checkLegCount ensures that 'raw', the number of legs, is between 1 and MAX_LEGS.
+func checkLegCount(raw string) (string, error) {
+
+	count, err := strconv.Atoi(raw)
+
+	if err != nil || count < 1 || count > MAX_LEGS {
+		return FINDING_LEG_COUNT_UNSUPPORTED, fmt.Errorf("Number of legs must be between 1 and %d, got '%s'", MAX_LEGS, raw)
+	}
+
+	return "", nil
+}
+
+// checkVersion ensures that 'raw', a version number, is between MIN_VERSION and MAX_VERSION.
+func checkVersion(raw string) (string, error) {
+
+	version, err := strconv.Atoi(raw)
+
+	if err != nil || version < MIN_VERSION || version > MAX_VERSION {
+		return FINDING_VERSION_UNSUPPORTED, fmt.Errorf("Version number must be between %d and %d, got '%s'", MIN_VERSION, MAX_VERSION, raw)
+	}
+
+	return "", nil
+}
@@ -0,0 +1,70 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestValidate checks BCBP strings which each violate Resolution 792 in a single way, and ensures that the
+// violation is reported with the expected code, field and severity.
+func TestValidate(t *testing.T) {
+
+	unsigned := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>6181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z"
+	conforming := unsigned + "^108ABCDEFGH"
+
+	tests := []struct {
+		name  string
+		raw   string
+		valid bool
+		// The expected finding, or "" if no warnings or errors are expected.
+		code     string
+		field    string
+		severity string
+	}{
+		{"conforming", conforming, true, "", "", ""},
+		{"lower_case_passenger_name", "M1desmarais/luc" + conforming[15:], true, FINDING_CHARACTER_CLASS, "passenger_name", SEVERITY_WARNING},
+		{"date_of_flight_out_of_range", conforming[0:44] + "400" + conforming[47:], false, FINDING_DATE_OUT_OF_RANGE, "date_of_flight", SEVERITY_ERROR},
+		{"invalid_airport", conforming[0:30] + "YU1" + conforming[33:], false, FINDING_CHARACTER_CLASS, "from_airport", SEVERITY_ERROR},
+		{"unsupported_version", conforming[0:61] + "9" + conforming[62:], false, FINDING_VERSION_UNSUPPORTED, "version_number", SEVERITY_ERROR},
+		{"field_size_mismatch", unsigned[0:58] + "4E" + unsigned[60:], false, WARNING_FIELD_SIZE_MISMATCH, "optional_data_size", SEVERITY_ERROR},
+		{"trailing_data", unsigned + "\r\n", true, WARNING_TRAILING_DATA, "", SEVERITY_WARNING},
+		{"security_length_mismatch", conforming + "XYZ", false, WARNING_SECURITY_LENGTH_MISMATCH, "security_data", SEVERITY_ERROR},
+		{"truncated", conforming[0:40], false, WARNING_MANDATORY_SECTION_TRUNCATED, "", SEVERITY_ERROR},
+		{"empty", "", false, ERROR_EMPTY_INPUT, "", SEVERITY_ERROR},
+	}
+
+	for _, tt := range tests {
+
+		r := Validate(tt.raw)
+
+		if r.Valid != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got %t (%d errors)", tt.name, tt.valid, r.Valid, r.Errors)
+		}
+
+		if tt.code == "" {
+
+			if r.Errors+r.Warnings != 0 {
+				t.Errorf("%s: expected no errors or warnings, got %d errors and %d warnings", tt.name, r.Errors, r.Warnings)
+			}
+
+			continue
+		}
+
+		found := false
+
+		for _, f := range r.Findings {
+
+			if f.Code == tt.code && (tt.field == "" || f.Field == tt.field) {
+
+				found = true
+
+				if f.Severity != tt.severity {
+					t.Errorf("%s: expected %s finding to have severity %s, got %s", tt.name, f.Code, tt.severity, f.Severity)
+				}
+			}
+		}
+
+		if !found {
+			t.Errorf("%s: expected a %s finding for '%s', got %d findings", tt.name, tt.code, tt.field, len(r.Findings))
+		}
+	}
+}
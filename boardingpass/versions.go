@@ -0,0 +1,81 @@
+package boardingpass
+
+// The conditional items, and the version of Resolution 792 which introduced each of them, are those listed in
+// the field descriptions and version history of the IATA Bar Coded Boarding Pass Implementation Guide (Attachment
+// 'A' to Resolution 792). Each version appends items to the end of the unique and repeated sections so the layout of
+// an earlier version is a prefix of the layout of a later one: version 3 appended the non-consecutive baggage tag
+// license plate numbers to the unique section and version 6 appended fast track to the repeated section. Conditional
+// items are always decoded using the layout of MAX_VERSION and those which are not defined by the version of a BCBP
+// string are reported using WARNING_ITEM_UNDEFINED.
+
+// MIN_VERSION is the earliest version of IATA Resolution 792 that defines conditional items.
+const MIN_VERSION int = 1
+
+// MAX_VERSION is the most recent version of IATA Resolution 792 known to this package.
+const MAX_VERSION int = 8
+
+// MAX_LEGS is the maximum number of legs that may be encoded in a single BCBP string.
+const MAX_LEGS int = 4
+
+// conditionalItem is a conditional item and the version of Resolution 792 in which it was introduced.
+type conditionalItem struct {
+	Name    string
+	Version int
+}
+
+// uniqueItems are the unique conditional items, in the order they are encoded.
+var uniqueItems = []*conditionalItem{
+	{"passenger_description", 1},
+	{"source_of_checkin", 1},
+	{"source_of_boarding_pass_issuance", 1},
+	{"date_of_issue", 1},
+	{"document_type", 1},
+	{"airline_designator_of_issuer", 1},
+	{"baggage_tag_license_plate", 1},
+	{"first_non_consecutive_baggage_tag", 3},
+	{"second_non_consecutive_baggage_tag", 3},
+}
+
+// repeatedItems are the repeated conditional items, in the order they are encoded.
+var repeatedItems = []*conditionalItem{
+	{"airline_numeric_code", 1},
+	{"document_serial_number", 1},
+	{"selectee_indicator", 1},
+	{"international_documentation_verification", 1},
+	{"marketing_carrier_designator", 1},
+	{"frequent_flyer_airline_designator", 1},
+	{"frequent_flyer_number", 1},
+	{"id_ad_indicator", 1},
+	{"free_baggage_allowance", 1},
+	{"fast_track", 6},
+}
+
+// itemVersion returns the version of Resolution 792 in which the conditional item 'name' was introduced, or 0 if
+// 'name' is not a unique or repeated conditional item.
+func itemVersion(name string) int {
+
+	for _, items := range [][]*conditionalItem{uniqueItems, repeatedItems} {
+
+		for _, i := range items {
+
+			if i.Name == name {
+				return i.Version
+			}
+		}
+	}
+
+	return 0
+}
+
+// checkItemVersion records a warning if the conditional item 'field', at 'offset', is not defined by the version
+// number declared by the first leg.
+func (d *decoder) checkItemVersion(field string, offset int, raw string) {
+
+	v := itemVersion(field)
+
+	if d.version == 0 || v <= d.version {
+		return
+	}
+
+	d.warn(WARNING_ITEM_UNDEFINED, field, offset, raw, "%s is not defined before version %d, got version %d", field, v, d.version)
+}
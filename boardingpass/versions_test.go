@@ -0,0 +1,151 @@
+package boardingpass
+
+import (
+	"testing"
+)
+
+// TestVersionLayouts decodes a BCBP string for every version of Resolution 792, containing each of the conditional
+// items defined by that version, and encodes it again.
+func TestVersionLayouts(t *testing.T) {
+
+	tests := []struct {
+		version int
+		raw     string
+		// Whether the non-consecutive baggage tags (version 3) and fast track (version 6) are present.
+		non_consecutive bool
+		fast_track      bool
+	}{
+		{1, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14C>1181WW6225BAC 001412345600129014123456789011AC AC 1234567890123   020KLX58Z", false, false},
+		{2, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14C>2181WW6225BAC 001412345600129014123456789011AC AC 1234567890123   020KLX58Z", false, false},
+		{3, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 166>3321WW6225BAC 00141234560010014123456003001412345600529014123456789011AC AC 1234567890123   020KLX58Z", true, false},
+		{4, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 166>4321WW6225BAC 00141234560010014123456003001412345600529014123456789011AC AC 1234567890123   020KLX58Z", true, false},
+		{5, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 166>5321WW6225BAC 00141234560010014123456003001412345600529014123456789011AC AC 1234567890123   020KLX58Z", true, false},
+		{6, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 167>6321WW6225BAC 0014123456001001412345600300141234560052A014123456789011AC AC 1234567890123   020KYLX58Z", true, true},
+		{7, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 167>7321WW6225BAC 0014123456001001412345600300141234560052A014123456789011AC AC 1234567890123   020KYLX58Z", true, true},
+		{8, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 167>8321WW6225BAC 0014123456001001412345600300141234560052A014123456789011AC AC 1234567890123   020KYLX58Z", true, true},
+	}
+
+	for _, tt := range tests {
+
+		b, err := Unmarshal(tt.raw)
+
+		if err != nil {
+			t.Errorf("Version %d: failed to parse, %v", tt.version, err)
+			continue
+		}
+
+		if b.Version != tt.version {
+			t.Errorf("Version %d: expected version %d, got %d", tt.version, tt.version, b.Version)
+		}
+
+		if hasWarning(b, WARNING_ITEM_UNDEFINED) {
+			t.Errorf("Version %d: unexpected %s warning", tt.version, WARNING_ITEM_UNDEFINED)
+		}
+
+		c := b.Legs[0].Conditional
+
+		expected := map[string]string{
+			"passenger_description":             "1",
+			"airline_designator_of_issuer":      "AC",
+			"document_serial_number":            "1234567890",
+			"frequent_flyer_airline_designator": "AC",
+			"frequent_flyer_number":             "1234567890123",
+			"id_ad_indicator":                   "0",
+			"free_baggage_allowance":            "20K",
+		}
+
+		actual := map[string]*Item[string]{
+			"passenger_description":             c.PassengerDescription,
+			"airline_designator_of_issuer":      c.AirlineDesignatorOfIssuer,
+			"document_serial_number":            c.DocumentSerialNumber,
+			"frequent_flyer_airline_designator": c.FrequentFlyerAirlineDesignator,
+			"frequent_flyer_number":             c.FrequentFlyerNumber,
+			"id_ad_indicator":                   c.IDADIndicator,
+			"free_baggage_allowance":            c.FreeBaggageAllowance,
+		}
+
+		for name, value := range expected {
+
+			if actual[name] == nil || actual[name].Value != value {
+				t.Errorf("Version %d: expected %s '%s', got %v", tt.version, name, value, actual[name])
+			}
+		}
+
+		if c.BaggageTagLicensePlate == nil || c.BaggageTagLicensePlate.Value.SerialNumber != "123456" || c.BaggageTagLicensePlate.Value.ConsecutiveTags != 1 {
+			t.Errorf("Version %d: unexpected baggage tag license plate %v", tt.version, c.BaggageTagLicensePlate)
+		}
+
+		if tt.non_consecutive != (c.FirstNonConsecutiveBaggageTag != nil && c.SecondNonConsecutiveBaggageTag != nil) {
+			t.Errorf("Version %d: expected non-consecutive baggage tags to be present: %t", tt.version, tt.non_consecutive)
+		}
+
+		if tt.non_consecutive && c.SecondNonConsecutiveBaggageTag.Value.ConsecutiveTags != 5 {
+			t.Errorf("Version %d: unexpected second non-consecutive baggage tag %v", tt.version, c.SecondNonConsecutiveBaggageTag)
+		}
+
+		if tt.fast_track != (c.FastTrack != nil && c.FastTrack.Value) {
+			t.Errorf("Version %d: expected fast track: %t", tt.version, tt.fast_track)
+		}
+
+		if c.AirlineData != "LX58Z" {
+			t.Errorf("Version %d: expected airline data 'LX58Z', got '%s'", tt.version, c.AirlineData)
+		}
+
+		enc, err := Marshal(b)
+
+		if err != nil {
+			t.Errorf("Version %d: failed to marshal, %v", tt.version, err)
+			continue
+		}
+
+		if enc != tt.raw {
+			t.Errorf("Version %d: expected '%s', got '%s'", tt.version, tt.raw, enc)
+		}
+	}
+}
+
+// TestVersionUndefinedItems decodes a version 3 BCBP string which contains fast track, an item introduced by
+// version 6, in both strict and lenient mode. Its ID/AD indicator is blank and its free baggage allowance is "20K".
+func TestVersionUndefinedItems(t *testing.T) {
+
+	raw := "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 226F001A0025 14D>3181WW6225BAC 00141234560032A0141234567890 1AC AC 1234567890123    20KYLX58Z^108ABCDEFGH"
+
+	for _, opts := range []*UnmarshalOptions{{}, {Lenient: true}} {
+
+		b, err := UnmarshalWithOptions(raw, opts)
+
+		if err != nil {
+			t.Errorf("Lenient %t: failed to parse, %v", opts.Lenient, err)
+			continue
+		}
+
+		c := b.Legs[0].Conditional
+
+		if c.IDADIndicator == nil || c.IDADIndicator.Raw != " " {
+			t.Errorf("Lenient %t: expected a blank ID/AD indicator, got %v", opts.Lenient, c.IDADIndicator)
+		}
+
+		if c.FreeBaggageAllowance == nil || c.FreeBaggageAllowance.Raw != "20K" {
+			t.Errorf("Lenient %t: expected free baggage allowance '20K', got %v", opts.Lenient, c.FreeBaggageAllowance)
+		}
+
+		if c.AirlineData != "LX58Z" {
+			t.Errorf("Lenient %t: expected airline data 'LX58Z', got '%s'", opts.Lenient, c.AirlineData)
+		}
+
+		if len(b.Warnings) != 1 || b.Warnings[0].Code != WARNING_ITEM_UNDEFINED || b.Warnings[0].Field != "fast_track" {
+			t.Errorf("Lenient %t: expected a single %s warning for fast_track, got %v", opts.Lenient, WARNING_ITEM_UNDEFINED, b.Warnings)
+		}
+
+		enc, err := Marshal(b)
+
+		if err != nil {
+			t.Errorf("Lenient %t: failed to marshal, %v", opts.Lenient, err)
+			continue
+		}
+
+		if enc != raw {
+			t.Errorf("Lenient %t: expected '%s', got '%s'", opts.Lenient, raw, enc)
+		}
+	}
+}
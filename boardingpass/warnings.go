@@ -0,0 +1,111 @@
+package boardingpass
+
+import (
+	"fmt"
+)
+
+// WARNING_BAD_FORMAT_CODE is reported when the format code is not "M".
+const WARNING_BAD_FORMAT_CODE string = "BAD_FORMAT_CODE"
+
+// WARNING_LEG_COUNT_INVALID is reported when the number of legs encoded is not a number.
+const WARNING_LEG_COUNT_INVALID string = "LEG_COUNT_INVALID"
+
+// WARNING_LEG_COUNT_MISMATCH is reported when the number of legs encoded does not match the number of legs found.
+const WARNING_LEG_COUNT_MISMATCH string = "LEG_COUNT_MISMATCH"
+
+// WARNING_EMPTY_LEG is reported when an empty leg, for example a trailing group separator, is skipped.
+const WARNING_EMPTY_LEG string = "EMPTY_LEG"
+
+// WARNING_PASSENGER_NAME_TRUNCATED is reported when a passenger name shorter than 20 characters has been padded
+// in order to realign the fields that follow it.
+const WARNING_PASSENGER_NAME_TRUNCATED string = "PASSENGER_NAME_TRUNCATED"
+
+// WARNING_MANDATORY_SECTION_TRUNCATED is reported when a leg is shorter than its mandatory items and has been padded.
+const WARNING_MANDATORY_SECTION_TRUNCATED string = "MANDATORY_SECTION_TRUNCATED"
+
+// WARNING_FIELD_SIZE_INVALID is reported when a field size is not a hexadecimal number.
+const WARNING_FIELD_SIZE_INVALID string = "FIELD_SIZE_INVALID"
+
+// WARNING_FIELD_SIZE_MISMATCH is reported when a field size exceeds the data available.
+const WARNING_FIELD_SIZE_MISMATCH string = "FIELD_SIZE_MISMATCH"
+
+// WARNING_ITEM_INVALID is reported when a conditional item can not be decoded. Its raw value is retained.
+const WARNING_ITEM_INVALID string = "ITEM_INVALID"
+
+// WARNING_CONDITIONAL_INVALID is reported when the conditional section of a leg can not be decoded.
+const WARNING_CONDITIONAL_INVALID string = "CONDITIONAL_INVALID"
+
+// WARNING_SECURITY_INVALID is reported when the security data section can not be decoded.
+const WARNING_SECURITY_INVALID string = "SECURITY_INVALID"
+
+// WARNING_SECURITY_LENGTH_MISMATCH is reported when the length of security data does not match the security data.
+const WARNING_SECURITY_LENGTH_MISMATCH string = "SECURITY_LENGTH_MISMATCH"
+
+// WARNING_TRAILING_DATA is reported when data that is neither a leg nor security data follows the last leg.
+const WARNING_TRAILING_DATA string = "TRAILING_DATA"
+
+// WARNING_ITEM_UNDEFINED is reported, in both strict and lenient mode, when a conditional item is present which is
+// not defined by the version of a BCBP string. The item is still decoded.
+const WARNING_ITEM_UNDEFINED string = "ITEM_UNDEFINED"
+
+// Warning describes a problem that was found while parsing a BCBP string. Most problems are only repaired, and
+// reported as warnings, in lenient mode and are returned as errors in strict mode. Conditional items which are not
+// defined by the version (WARNING_ITEM_UNDEFINED), conditional sections and security data which can not be decoded
+// (WARNING_CONDITIONAL_INVALID and WARNING_SECURITY_INVALID) and trailing data (WARNING_TRAILING_DATA) are reported
+// as warnings in both modes.
+type Warning struct {
+	// A machine-readable code for the problem. One of the WARNING_ constants.
+	Code string `json:"code"`
+	// The name of the field, or conditional item, where the problem was found.
+	Field string `json:"field"`
+	// The offset of the problem in the BCBP string.
+	Offset int `json:"offset"`
+	// The index of the leg where the problem was found.
+	LegIndex int `json:"leg_index"`
+	// The raw characters where the problem was found.
+	Raw string `json:"raw"`
+	// A human-readable description of the problem and how it was repaired.
+	Message string `json:"message"`
+}
+
+// decoder keeps track of whether problems should be repaired, and recorded as warnings, or returned as errors.
+type decoder struct {
+	lenient  bool
+	warnings []*Warning
+	// The index of the leg currently being decoded.
+	leg int
+	// Whether or not to record the location of each field.
+	field_map bool
+	// The locations of the fields decoded since this value was last reset.
+	locations []*FieldLocation
+	// Whether or not to record the exact characters of each leg.
+	lossless bool
+	// The version number declared by the first leg, or 0 if it has not been decoded.
+	version int
+}
+
+// recover returns 'err' as an `Error` unless the decoder is lenient in which case 'err' is recorded as a warning
+// and nil is returned. In both cases the problem is identified by the same 'code'.
+func (d *decoder) recover(err error, code string, field string, offset int, raw string) error {
+
+	if !d.lenient {
+		return d.fail(err, code, field, offset, raw)
+	}
+
+	d.warn(code, field, offset, raw, "%v", err)
+	return nil
+}
+
+func (d *decoder) warn(code string, field string, offset int, raw string, msg string, args ...any) {
+
+	w := &Warning{
+		Code:     code,
+		Field:    field,
+		Offset:   offset,
+		LegIndex: d.leg,
+		Raw:      raw,
+		Message:  fmt.Sprintf(msg, args...),
+	}
+
+	d.warnings = append(d.warnings, w)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// BuildFunc returns a JavaScript function which encodes a `ParseResponse`, either as a JavaScript object or a
+// JSON-encoded string, as a BCBP string and resolves a Promise with that string.
+func BuildFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			if len(args) == 0 {
+				err := fmt.Errorf("Missing BCBP data")
+				slog.Error("Failed to build BCBP", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			var rsp *ParseResponse
+
+			err := fromJSValue(args[0], &rsp)
+
+			if err != nil {
+				err = fmt.Errorf("Invalid BCBP data, %w", err)
+				slog.Error("Failed to build BCBP", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			if rsp == nil {
+				err := fmt.Errorf("Invalid BCBP data, expected an object but got null")
+				slog.Error("Failed to build BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+				return
+			}
+
+			opts := &boardingpass.MarshalOptions{}
+
+			if len(args) > 1 {
+				opts.GroupSeparator = boolOption(args[1], "group_separator")
+			}
+
+			bcbp_str, err := build(rsp, opts)
+
+			if err != nil {
+				slog.Error("Failed to build BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+				return
+			}
+
+			resolve.Invoke(bcbp_str)
+		})
+	})
+}
+
+// build encodes 'rsp' as a BCBP string using 'opts'.
+func build(rsp *ParseResponse, opts *boardingpass.MarshalOptions) (string, error) {
+
+	b := &boardingpass.BCBP{
+		Legs:     make([]*boardingpass.Leg, len(rsp.Legs)),
+		Security: rsp.Security,
+		Trailing: rsp.Trailing,
+	}
+
+	for idx, l := range rsp.Legs {
+
+		if l == nil {
+			return "", fmt.Errorf("Leg %d is empty", idx)
+		}
+
+		b.Legs[idx] = &boardingpass.Leg{
+			Leg:         l.Fields,
+			Conditional: l.Conditional,
+			Raw:         l.Raw,
+		}
+	}
+
+	return boardingpass.MarshalWithOptions(b, opts)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"syscall/js"
+)
+
+// toJSValue converts 'v' in to native JavaScript objects, arrays, strings, numbers, booleans and nulls. The names
+// (and omission) of the properties of structs follow their `json` struct tags, as they would for `json.Marshal`, but
+// the values are created directly rather than being encoded as JSON and parsed again.
+func toJSValue(v any) (js.Value, error) {
+	return reflectJSValue(reflect.ValueOf(v))
+}
+
+// reflectJSValue converts 'rv' in to a native JavaScript value. Values which implement `json.Marshaler` are
+// encoded as JSON and parsed using `JSON.parse`.
+func reflectJSValue(rv reflect.Value) (js.Value, error) {
+
+	if !rv.IsValid() {
+		return js.Null(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+
+		if rv.IsNil() {
+			return js.Null(), nil
+		}
+	}
+
+	m, ok := rv.Interface().(json.Marshaler)
+
+	if ok {
+
+		enc, err := m.MarshalJSON()
+
+		if err != nil {
+			return js.Undefined(), fmt.Errorf("Failed to marshal value, %w", err)
+		}
+
+		return js.Global().Get("JSON").Call("parse", string(enc)), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return reflectJSValue(rv.Elem())
+	case reflect.Struct:
+
+		obj := js.Global().Get("Object").New()
+
+		err := setJSFields(obj, rv)
+
+		if err != nil {
+			return js.Undefined(), err
+		}
+
+		return obj, nil
+
+	case reflect.Map:
+
+		if rv.Type().Key().Kind() != reflect.String {
+			return js.Undefined(), fmt.Errorf("Unsupported map key type %s", rv.Type().Key())
+		}
+
+		obj := js.Global().Get("Object").New()
+		iter := rv.MapRange()
+
+		for iter.Next() {
+
+			v, err := reflectJSValue(iter.Value())
+
+			if err != nil {
+				return js.Undefined(), err
+			}
+
+			obj.Set(iter.Key().String(), v)
+		}
+
+		return obj, nil
+
+	case reflect.Slice, reflect.Array:
+
+		// As with json.Marshal byte slices are encoded as base64 strings
+
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return js.ValueOf(base64.StdEncoding.EncodeToString(rv.Bytes())), nil
+		}
+
+		arr := js.Global().Get("Array").New(rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+
+			v, err := reflectJSValue(rv.Index(i))
+
+			if err != nil {
+				return js.Undefined(), err
+			}
+
+			arr.SetIndex(i, v)
+		}
+
+		return arr, nil
+
+	case reflect.String:
+		return js.ValueOf(rv.String()), nil
+	case reflect.Bool:
+		return js.ValueOf(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return js.ValueOf(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return js.ValueOf(float64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return js.ValueOf(rv.Float()), nil
+	default:
+		return js.Undefined(), fmt.Errorf("Unsupported type %s", rv.Type())
+	}
+}
+
+// setJSFields assigns the exported fields of the struct 'rv' to the JavaScript object 'obj' using the names, and
+// "omitempty" options, of their `json` struct tags. The fields of embedded structs without a name are assigned to
+// 'obj' itself.
+func setJSFields(obj js.Value, rv reflect.Value) error {
+
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+
+		if f.Anonymous && name == "" {
+
+			if fv.Kind() == reflect.Pointer {
+
+				if fv.IsNil() {
+					continue
+				}
+
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+
+				err := setJSFields(obj, fv)
+
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+
+		v, err := reflectJSValue(fv)
+
+		if err != nil {
+			return fmt.Errorf("Failed to convert %s, %w", f.Name, err)
+		}
+
+		obj.Set(name, v)
+	}
+
+	return nil
+}
+
+// isEmptyValue reports whether 'rv' is omitted by the "omitempty" option of a `json` struct tag.
+func isEmptyValue(rv reflect.Value) bool {
+
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	default:
+		return false
+	}
+}
+
+// fromJSValue decodes 'v', either a JavaScript object or a JSON-encoded string, in to 'target'. Objects are
+// first encoded using `JSON.stringify` so the names of properties are matched using the `json` struct tags of
+// 'target'.
+func fromJSValue(v js.Value, target any) error {
+
+	var enc string
+
+	switch v.Type() {
+	case js.TypeString:
+		enc = v.String()
+	case js.TypeObject:
+		enc = js.Global().Get("JSON").Call("stringify", v).String()
+	default:
+		return fmt.Errorf("Invalid value, expected an object or a JSON-encoded string")
+	}
+
+	err := json.Unmarshal([]byte(enc), target)
+
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal value, %w", err)
+	}
+
+	return nil
+}
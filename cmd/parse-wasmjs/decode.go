@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/barcode"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// DEFAULT_BARCODE_SCHEME is the scheme of the `bcbp.Barcode` used to decode images when no "scheme" option is
+// specified.
+const DEFAULT_BARCODE_SCHEME string = "pdf417://"
+
+// DecodeImageFunc returns a JavaScript function which decodes the barcode symbol in a PNG, JPEG or GIF image,
+// passed as a `Uint8Array`, parses its text as a BCBP string and resolves a Promise with a JSON-encoded
+// `ParseResponse` string whose 'Symbology' property is the symbology of the barcode. It accepts the same options
+// as `parse_bcbp` along with a "scheme" option, the URI of the `bcbp.Barcode` used to decode the image, and an
+// "object" option which, if true, resolves the Promise with native JavaScript objects and arrays, as
+// `parse_bcbp_object` does, instead of a JSON-encoded string.
+func DecodeImageFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			if len(args) == 0 || args[0].Type() != js.TypeObject || !args[0].InstanceOf(js.Global().Get("Uint8Array")) {
+				err := fmt.Errorf("Missing or invalid image data, expected a Uint8Array")
+				slog.Error("Failed to decode image", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			data := make([]byte, args[0].Get("length").Int())
+			js.CopyBytesToGo(data, args[0])
+
+			opts := js.Undefined()
+
+			if len(args) > 1 {
+				opts = args[1]
+			}
+
+			parse_opts, reference, overrides, err := parseOptions(opts)
+
+			if err != nil {
+				slog.Error("Failed to decode image", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			scheme := stringOption(opts, "scheme", DEFAULT_BARCODE_SCHEME)
+
+			slog.Info("Decode image", "scheme", scheme, "length", len(data))
+
+			ctx := context.Background()
+			s, err := barcode.DecodeSymbol(ctx, scheme, bytes.NewReader(data))
+
+			if err != nil {
+				slog.Error("Failed to decode image", "error", err)
+				reject.Invoke(newJSError(err, ERROR_BARCODE_UNREADABLE))
+				return
+			}
+
+			rsp, err := parse(s.Text, parse_opts, reference, overrides)
+
+			if err != nil {
+				reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+				return
+			}
+
+			rsp.Symbology = s.Symbology
+
+			if boolOption(opts, "object") {
+
+				obj, err := toJSValue(rsp)
+
+				if err != nil {
+					slog.Error("Failed to convert BCBP", "error", err)
+					reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+					return
+				}
+
+				resolve.Invoke(obj)
+				return
+			}
+
+			enc, err := json.Marshal(rsp)
+
+			if err != nil {
+				slog.Error("Failed to marshal BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+				return
+			}
+
+			resolve.Invoke(string(enc))
+		})
+	})
+}
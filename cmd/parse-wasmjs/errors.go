@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// ERROR_INVALID_ARGUMENT is the code assigned to errors caused by missing or invalid function arguments.
+const ERROR_INVALID_ARGUMENT string = "INVALID_ARGUMENT"
+
+// ERROR_BARCODE_UNREADABLE is the code assigned to errors caused by images which do not contain a barcode symbol
+// that can be decoded.
+const ERROR_BARCODE_UNREADABLE string = "BARCODE_UNREADABLE"
+
+// newJSError returns a new JavaScript `Error` for 'err' with "code", "field", "offset", "leg_index" and "raw"
+// properties. If 'err' is (or wraps) a `boardingpass.Error` these are derived from it, otherwise "code" is
+// assigned 'code' and the remaining properties are null.
+func newJSError(err error, code string) js.Value {
+
+	js_err := js.Global().Get("Error").New(err.Error())
+
+	js_err.Set("code", code)
+	js_err.Set("field", js.Null())
+	js_err.Set("offset", js.Null())
+	js_err.Set("leg_index", js.Null())
+	js_err.Set("raw", js.Null())
+
+	var bp_err *boardingpass.Error
+
+	if !errors.As(err, &bp_err) {
+		return js_err
+	}
+
+	js_err.Set("code", bp_err.Code)
+	js_err.Set("raw", bp_err.Raw)
+
+	if bp_err.Field != "" {
+		js_err.Set("field", bp_err.Field)
+	}
+
+	if bp_err.Offset > -1 {
+		js_err.Set("offset", bp_err.Offset)
+	}
+
+	if bp_err.LegIndex > -1 {
+		js_err.Set("leg_index", bp_err.LegIndex)
+	}
+
+	return js_err
+}
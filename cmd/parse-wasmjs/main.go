@@ -5,81 +5,361 @@ import (
 	"fmt"
 	"log/slog"
 	"syscall/js"
+	"time"
 
 	"github.com/sfomuseum/go-bcbp"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
 )
 
 type LegResponse struct {
-	Fields *bcbp.Leg `json:"fields"`
-	Month  int        `json:"month"`
-	Day    int        `json:"day"`	
+	Fields           *bcbp.Leg                                `json:"fields"`
+	Month            int                                      `json:"month"`
+	Day              int                                      `json:"day"`
+	Date             string                                   `json:"date,omitempty"`
+	FlightDate       *boardingpass.FlightDate                 `json:"flight_date,omitempty"`
+	Conditional      *boardingpass.Conditional                `json:"conditional,omitempty"`
+	FieldMap         []*boardingpass.FieldLocation            `json:"field_map,omitempty"`
+	Raw              *boardingpass.RawLeg                     `json:"raw,omitempty"`
+	Seat             *boardingpass.Seat                       `json:"seat,omitempty"`
+	Cabin            string                                   `json:"cabin,omitempty"`
+	Descriptions     map[string]*boardingpass.CodeDescription `json:"descriptions,omitempty"`
+	Ticket           *boardingpass.Ticket                     `json:"ticket,omitempty"`
+	BaggageAllowance *boardingpass.BaggageAllowance           `json:"baggage_allowance,omitempty"`
+	FrequentFlyer    *boardingpass.FrequentFlyer              `json:"frequent_flyer,omitempty"`
 }
 
 type ParseResponse struct {
-	Raw    string     `json:"raw"`
-	Legs []*LegResponse `json:"legs"`
+	Raw       string                      `json:"raw"`
+	Strategy  string                      `json:"strategy"`
+	Version   int                         `json:"version,omitempty"`
+	Passenger *boardingpass.PassengerName `json:"passenger,omitempty"`
+	Legs      []*LegResponse              `json:"legs"`
+	Security  *boardingpass.Security      `json:"security,omitempty"`
+	Warnings  []*boardingpass.Warning     `json:"warnings,omitempty"`
+	Trailing  string                      `json:"trailing,omitempty"`
+	Symbology string                      `json:"symbology,omitempty"`
 }
 
+// ParseFunc returns a JavaScript function which parses a BCBP string and resolves a Promise with a JSON-encoded
+// `ParseResponse` string.
 func ParseFunc() js.Func {
 
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 
-		bcbp_str := args[0].String()
+		return newPromise(func(resolve js.Value, reject js.Value) {
 
-		logger := slog.Default()
-		logger = logger.With("raw", bcbp_str)
+			rsp, err := parseWithArgs(args, reject)
 
-		logger.Info("Parse BCBP")
-
-		handler := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-
-			resolve := args[0]
-			reject := args[1]
+			if err != nil {
+				return
+			}
 
-			b, err := bcbp.Unmarshal(bcbp_str)
+			enc, err := json.Marshal(rsp)
 
 			if err != nil {
-				logger.Error("Failed to parse BCBP", "error", err)
-				reject.Invoke(fmt.Printf("Failed to parse '%s', %v\n", bcbp_str, err))
-				return nil
+				slog.Error("Failed to marshal BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+				return
 			}
 
-			rsp := ParseResponse{
-				Raw:    bcbp_str,
-				Legs: make([]*LegResponse, len(b.Legs)),
-			}
+			resolve.Invoke(string(enc))
+		})
+	})
+}
 
-			for idx, l := range b.Legs {
+// ParseObjectFunc returns a JavaScript function which parses a BCBP string and resolves a Promise with a
+// `ParseResponse` encoded as native JavaScript objects and arrays.
+func ParseObjectFunc() js.Func {
 
-				rsp.Legs[idx] = &LegResponse{
-					Fields: l,
-				}
-				
-				m, d, err := l.MonthDay()
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 
-				if err != nil {
-					logger.Error("Failed to derive month/day from date of flight", "error", err)
-				} else {
-					rsp.Legs[idx].Month = m
-					rsp.Legs[idx].Day = d
-				}
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			rsp, err := parseWithArgs(args, reject)
+
+			if err != nil {
+				return
 			}
-			
-			enc, err := json.Marshal(rsp)
+
+			obj, err := toJSValue(rsp)
 
 			if err != nil {
-				logger.Error("Failed to marshal BCBP", "error", err)
-				reject.Invoke(fmt.Printf("Failed to marshal result for '%s', %v\n", bcbp_str, err))
-				return nil
+				slog.Error("Failed to convert BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+				return
 			}
 
-			resolve.Invoke(string(enc))
-			return nil
+			resolve.Invoke(obj)
 		})
+	})
+}
+
+// parseWithArgs parses the BCBP string and options in 'args' returning a `ParseResponse`. If there is a problem
+// 'reject' is invoked with a JavaScript `Error` and the underlying error is returned.
+func parseWithArgs(args []js.Value, reject js.Value) (*ParseResponse, error) {
+
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		err := fmt.Errorf("Missing or invalid BCBP string")
+		slog.Error("Failed to parse BCBP", "error", err)
+		reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+		return nil, err
+	}
+
+	bcbp_str := args[0].String()
+
+	opts := js.Undefined()
+
+	if len(args) > 1 {
+		opts = args[1]
+	}
+
+	parse_opts, reference, overrides, err := parseOptions(opts)
+
+	if err != nil {
+		slog.Error("Failed to parse BCBP", "error", err)
+		reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+		return nil, err
+	}
+
+	rsp, err := parse(bcbp_str, parse_opts, reference, overrides)
+
+	if err != nil {
+		reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+// parseOptions returns the `boardingpass.UnmarshalOptions`, reference date and cabin overrides defined by the
+// "lenient", "field_map", "lossless", "reference_date" and "cabins" properties of the JavaScript object 'opts'. If
+// 'opts' is not an object the defaults are returned.
+func parseOptions(opts js.Value) (*boardingpass.UnmarshalOptions, time.Time, boardingpass.CabinOverrides, error) {
+
+	parse_opts := &boardingpass.UnmarshalOptions{
+		Lenient:  boolOption(opts, "lenient"),
+		FieldMap: boolOption(opts, "field_map"),
+		Lossless: boolOption(opts, "lossless"),
+	}
+
+	reference := time.Now()
+
+	ref, err := dateOption(opts, "reference_date")
+
+	if err != nil {
+		return nil, reference, nil, err
+	}
+
+	if !ref.IsZero() {
+		reference = ref
+	}
+
+	overrides, err := cabinsOption(opts, "cabins")
+
+	if err != nil {
+		return nil, reference, nil, err
+	}
+
+	return parse_opts, reference, overrides, nil
+}
+
+// boolOption returns the value of the boolean property 'name' in the JavaScript object 'opts'. If 'opts' is
+// not an object or the property is not a boolean false is returned.
+func boolOption(opts js.Value, name string) bool {
+
+	if opts.Type() != js.TypeObject {
+		return false
+	}
+
+	v := opts.Get(name)
+
+	if v.Type() != js.TypeBoolean {
+		return false
+	}
+
+	return v.Bool()
+}
+
+// stringOption returns the value of the string property 'name' in the JavaScript object 'opts'. If 'opts' is
+// not an object or the property is not a string 'fallback' is returned.
+func stringOption(opts js.Value, name string, fallback string) string {
+
+	if opts.Type() != js.TypeObject {
+		return fallback
+	}
+
+	v := opts.Get(name)
+
+	if v.Type() != js.TypeString {
+		return fallback
+	}
+
+	return v.String()
+}
+
+// dateOption returns the value of the ISO-8601 date (or RFC 3339 date and time) property 'name' in the JavaScript
+// object 'opts'. If 'opts' is not an object or the property is not defined a zero `time.Time` is returned.
+func dateOption(opts js.Value, name string) (time.Time, error) {
 
-		promiseConstructor := js.Global().Get("Promise")
-		return promiseConstructor.New(handler)
+	if opts.Type() != js.TypeObject {
+		return time.Time{}, nil
+	}
+
+	v := opts.Get(name)
+
+	if v.Type() == js.TypeUndefined || v.Type() == js.TypeNull {
+		return time.Time{}, nil
+	}
+
+	if v.Type() != js.TypeString {
+		return time.Time{}, fmt.Errorf("Invalid %s option, expected a string", name)
+	}
+
+	t, err := time.Parse(time.RFC3339, v.String())
+
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.Parse(boardingpass.ISO_8601_DATE, v.String())
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid %s option '%s', %w", name, v.String(), err)
+	}
+
+	return t, nil
+}
+
+// cabinsOption returns the value of the property 'name' in the JavaScript object 'opts' as `CabinOverrides`. The
+// property is expected to be an object mapping airline designators to objects mapping compartment codes to cabin
+// descriptors. If 'opts' is not an object or the property is not defined an empty `CabinOverrides` is returned.
+func cabinsOption(opts js.Value, name string) (boardingpass.CabinOverrides, error) {
+
+	overrides := boardingpass.CabinOverrides{}
+
+	if opts.Type() != js.TypeObject {
+		return overrides, nil
+	}
+
+	v := opts.Get(name)
+
+	if v.Type() == js.TypeUndefined || v.Type() == js.TypeNull {
+		return overrides, nil
+	}
+
+	err := fromJSValue(v, &overrides)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s option, %w", name, err)
+	}
+
+	return overrides, nil
+}
+
+// parse parses 'bcbp_str' using 'opts' returning a `ParseResponse`. The date of flight of each leg is resolved
+// to a calendar date relative to 'reference' and the compartment code of each leg is resolved to a cabin using
+// 'overrides'.
+func parse(bcbp_str string, opts *boardingpass.UnmarshalOptions, reference time.Time, overrides boardingpass.CabinOverrides) (*ParseResponse, error) {
+
+	logger := slog.Default()
+	logger = logger.With("raw", bcbp_str)
+	logger = logger.With("lenient", opts.Lenient)
+
+	logger.Info("Parse BCBP")
+
+	b, err := boardingpass.UnmarshalWithOptions(bcbp_str, opts)
+
+	if err != nil {
+		logger.Error("Failed to parse BCBP", "error", err)
+		return nil, err
+	}
+
+	rsp := &ParseResponse{
+		Raw:       bcbp_str,
+		Strategy:  b.Strategy,
+		Version:   b.Version,
+		Passenger: b.Passenger(),
+		Legs:      make([]*LegResponse, len(b.Legs)),
+		Security:  b.Security,
+		Warnings:  b.Warnings,
+		Trailing:  b.Trailing,
+	}
+
+	for idx, l := range b.Legs {
+
+		rsp.Legs[idx] = &LegResponse{
+			Fields:       l.Leg,
+			Conditional:  l.Conditional,
+			FieldMap:     l.FieldMap,
+			Raw:          l.Raw,
+			Seat:         l.Seat(),
+			Cabin:        l.Cabin(overrides),
+			Descriptions: l.Descriptions(),
+		}
+
+		fd, err := b.FlightDate(idx, reference)
+
+		if err != nil {
+			logger.Error("Failed to derive date from date of flight", "error", err)
+		} else {
+			rsp.Legs[idx].Month = fd.Month
+			rsp.Legs[idx].Day = fd.Day
+			rsp.Legs[idx].Date = fd.Date
+			rsp.Legs[idx].FlightDate = fd
+		}
+
+		t, err := l.Ticket()
+
+		if err != nil {
+			logger.Error("Failed to derive ticket number", "error", err)
+		} else {
+			rsp.Legs[idx].Ticket = t
+		}
+
+		a, err := l.BaggageAllowance()
+
+		if err != nil {
+			logger.Error("Failed to decode free baggage allowance", "error", err)
+		} else {
+			rsp.Legs[idx].BaggageAllowance = a
+		}
+
+		rsp.Legs[idx].FrequentFlyer = l.FrequentFlyer()
+	}
+
+	return rsp, nil
+}
+
+// newPromise returns a new JavaScript Promise whose executor calls 'handler_func'. Any panics triggered by
+// 'handler_func' are recovered and used to reject the Promise. Without this a panic would terminate the Go
+// runtime and every subsequent call to an exported function would fail.
+func newPromise(handler_func func(resolve js.Value, reject js.Value)) js.Value {
+
+	handler := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		resolve := args[0]
+		reject := args[1]
+
+		defer func() {
+
+			r := recover()
+
+			if r != nil {
+				slog.Error("Recovered from panic", "error", r)
+				err := fmt.Errorf("Unexpected error, %v", r)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+			}
+		}()
+
+		handler_func(resolve, reject)
+		return nil
 	})
+
+	// The executor is called synchronously by the Promise constructor so it is safe to release it immediately
+	defer handler.Release()
+
+	promiseConstructor := js.Global().Get("Promise")
+	return promiseConstructor.New(handler)
 }
 
 func main() {
@@ -87,11 +367,39 @@ func main() {
 	parse_func := ParseFunc()
 	defer parse_func.Release()
 
+	parse_object_func := ParseObjectFunc()
+	defer parse_object_func.Release()
+
+	validate_func := ValidateFunc()
+	defer validate_func.Release()
+
+	build_func := BuildFunc()
+	defer build_func.Release()
+
+	normalize_func := NormalizeFunc()
+	defer normalize_func.Release()
+
+	decode_image_func := DecodeImageFunc()
+	defer decode_image_func.Release()
+
+	render_func := RenderFunc()
+	defer render_func.Release()
+
+	render_svg_func := RenderSVGFunc()
+	defer render_svg_func.Release()
+
 	js.Global().Set("parse_bcbp", parse_func)
+	js.Global().Set("parse_bcbp_object", parse_object_func)
+	js.Global().Set("validate_bcbp", validate_func)
+	js.Global().Set("build_bcbp", build_func)
+	js.Global().Set("normalize_bcbp", normalize_func)
+	js.Global().Set("decode_bcbp_image", decode_image_func)
+	js.Global().Set("render_bcbp_barcode", render_func)
+	js.Global().Set("render_bcbp_svg", render_svg_func)
 
 	c := make(chan struct{}, 0)
 
-	slog.Info("WASM parse_bcbp function initialized")
+	slog.Info("WASM parse_bcbp functions initialized")
 	<-c
 
 }
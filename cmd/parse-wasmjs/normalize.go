@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+type NormalizeResponse struct {
+	Raw        string                 `json:"raw"`
+	Normalized string                 `json:"normalized"`
+	Changes    []*boardingpass.Change `json:"changes"`
+}
+
+// NormalizeFunc returns a JavaScript function which derives the canonical form of a BCBP string and resolves a
+// Promise with a `NormalizeResponse` encoded as native JavaScript objects and arrays.
+func NormalizeFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			if len(args) == 0 || args[0].Type() != js.TypeString {
+				err := fmt.Errorf("Missing or invalid BCBP string")
+				slog.Error("Failed to normalize BCBP", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			bcbp_str := args[0].String()
+
+			slog.Info("Normalize BCBP", "raw", bcbp_str)
+
+			normalized, changes, err := boardingpass.Normalize(bcbp_str)
+
+			if err != nil {
+				slog.Error("Failed to normalize BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+				return
+			}
+
+			rsp := &NormalizeResponse{
+				Raw:        bcbp_str,
+				Normalized: normalized,
+				Changes:    changes,
+			}
+
+			obj, err := toJSValue(rsp)
+
+			if err != nil {
+				slog.Error("Failed to convert normalized BCBP", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+				return
+			}
+
+			resolve.Invoke(obj)
+		})
+	})
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/barcode"
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// DEFAULT_RENDER_SYMBOLOGY is the symbology of the barcodes rendered when no "symbology" option is specified.
+const DEFAULT_RENDER_SYMBOLOGY string = "pdf417"
+
+// RenderFunc returns a JavaScript function which renders a BCBP string, or a `ParseResponse` as a JavaScript
+// object, as a barcode symbol in a PNG image and resolves a Promise with a `Uint8Array` or, if the "data_uri" option
+// is true, a "data:" URI. The "symbology" ("pdf417" or "aztec"), "module_size", "quiet_zone" and "error_correction"
+// options are passed to the `bcbp.Barcode` used to encode the symbol.
+func RenderFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			bcbp_str, uri, opts, err := renderWithArgs(args, reject)
+
+			if err != nil {
+				return
+			}
+
+			var buf bytes.Buffer
+
+			ctx := context.Background()
+			err = barcode.EncodeSymbol(ctx, uri, bcbp_str, &buf)
+
+			if err != nil {
+				slog.Error("Failed to render barcode", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			if boolOption(opts, "data_uri") {
+				resolve.Invoke("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+				return
+			}
+
+			data := js.Global().Get("Uint8Array").New(buf.Len())
+			js.CopyBytesToJS(data, buf.Bytes())
+
+			resolve.Invoke(data)
+		})
+	})
+}
+
+// RenderSVGFunc returns a JavaScript function which renders a BCBP string, or a `ParseResponse` as a JavaScript
+// object, as a barcode symbol in an SVG document and resolves a Promise with the document or, if the "data_uri"
+// option is true, a "data:" URI. It accepts the same options as `render_bcbp_barcode` along with the "foreground",
+// "background", "width", "height", "text", "font_family" and "font_size" options used to derive a
+// `barcode.SVGOptions` instance.
+func RenderSVGFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			bcbp_str, uri, opts, err := renderWithArgs(args, reject)
+
+			if err != nil {
+				return
+			}
+
+			svg_opts, err := svgOptions(opts, bcbp_str)
+
+			if err != nil {
+				slog.Error("Failed to render barcode", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			var buf bytes.Buffer
+
+			ctx := context.Background()
+			err = barcode.EncodeSVG(ctx, uri, bcbp_str, svg_opts, &buf)
+
+			if err != nil {
+				slog.Error("Failed to render barcode", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			if boolOption(opts, "data_uri") {
+				resolve.Invoke("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+				return
+			}
+
+			resolve.Invoke(buf.String())
+		})
+	})
+}
+
+// renderWithArgs returns the BCBP string in 'args' to render, the URI of the `bcbp.Barcode` used to render it and
+// the options in 'args'. If there is a problem 'reject' is invoked with a JavaScript `Error` and the underlying
+// error is returned.
+func renderWithArgs(args []js.Value, reject js.Value) (string, string, js.Value, error) {
+
+	opts := js.Undefined()
+
+	if len(args) == 0 || (args[0].Type() != js.TypeString && args[0].Type() != js.TypeObject) {
+		err := fmt.Errorf("Missing or invalid BCBP data, expected a string or an object")
+		slog.Error("Failed to render barcode", "error", err)
+		reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+		return "", "", opts, err
+	}
+
+	if len(args) > 1 {
+		opts = args[1]
+	}
+
+	bcbp_str, err := renderText(args[0])
+
+	if err != nil {
+		slog.Error("Failed to render barcode", "error", err)
+		reject.Invoke(newJSError(err, boardingpass.ERROR_FIELD_INVALID))
+		return "", "", opts, err
+	}
+
+	uri, err := renderURI(opts)
+
+	if err != nil {
+		slog.Error("Failed to render barcode", "error", err)
+		reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+		return "", "", opts, err
+	}
+
+	slog.Info("Render barcode", "uri", uri, "raw", bcbp_str)
+
+	return bcbp_str, uri, opts, nil
+}
+
+// svgOptions returns the `barcode.SVGOptions` defined by the "foreground", "background", "width", "height",
+// "text", "font_family" and "font_size" properties of the JavaScript object 'opts'. If the "text" property is true
+// the human-readable text is 'bcbp_str'.
+func svgOptions(opts js.Value, bcbp_str string) (*barcode.SVGOptions, error) {
+
+	width, err := lengthOption(opts, "width")
+
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := lengthOption(opts, "height")
+
+	if err != nil {
+		return nil, err
+	}
+
+	svg_opts := &barcode.SVGOptions{
+		Foreground: stringOption(opts, "foreground", ""),
+		Background: stringOption(opts, "background", ""),
+		Width:      width,
+		Height:     height,
+		Text:       stringOption(opts, "text", ""),
+		FontFamily: stringOption(opts, "font_family", ""),
+	}
+
+	if boolOption(opts, "text") {
+		svg_opts.Text = bcbp_str
+	}
+
+	if opts.Type() == js.TypeObject && opts.Get("font_size").Type() != js.TypeUndefined {
+
+		v := opts.Get("font_size")
+
+		if v.Type() != js.TypeNumber || v.Float() <= 0 {
+			return nil, fmt.Errorf("Invalid font_size option, expected a positive number")
+		}
+
+		svg_opts.FontSize = v.Float()
+	}
+
+	return svg_opts, nil
+}
+
+// lengthOption returns the value of the property 'name' in the JavaScript object 'opts' as an SVG length. The
+// property may be a number, measured in pixels, or a string such as "80mm". If 'opts' is not an object or the
+// property is not defined an empty string is returned.
+func lengthOption(opts js.Value, name string) (string, error) {
+
+	if opts.Type() != js.TypeObject {
+		return "", nil
+	}
+
+	v := opts.Get(name)
+
+	switch v.Type() {
+	case js.TypeUndefined, js.TypeNull:
+		return "", nil
+	case js.TypeNumber:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case js.TypeString:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("Invalid %s option, expected a number or a string", name)
+	}
+}
+
+// renderText returns the BCBP string to render for 'v', either a BCBP string, which is returned as-is, or a
+// `ParseResponse` as a JavaScript object which is encoded as a BCBP string.
+func renderText(v js.Value) (string, error) {
+
+	if v.Type() == js.TypeString {
+		return v.String(), nil
+	}
+
+	var rsp *ParseResponse
+
+	err := fromJSValue(v, &rsp)
+
+	if err != nil {
+		return "", fmt.Errorf("Invalid BCBP data, %w", err)
+	}
+
+	if rsp == nil {
+		return "", fmt.Errorf("Invalid BCBP data, expected an object but got null")
+	}
+
+	return build(rsp, &boardingpass.MarshalOptions{})
+}
+
+// renderURI returns the URI of the `bcbp.Barcode` used to render barcodes derived from the "symbology",
+// "module_size", "quiet_zone" and "error_correction" properties of the JavaScript object 'opts'.
+func renderURI(opts js.Value) (string, error) {
+
+	symbology := stringOption(opts, "symbology", DEFAULT_RENDER_SYMBOLOGY)
+
+	switch symbology {
+	case barcode.SYMBOLOGY_PDF417, barcode.SYMBOLOGY_AZTEC:
+		// pass
+	default:
+		return "", fmt.Errorf("Invalid symbology option '%s', expected '%s' or '%s'", symbology, barcode.SYMBOLOGY_PDF417, barcode.SYMBOLOGY_AZTEC)
+	}
+
+	q := url.Values{}
+
+	for _, name := range []string{"module_size", "quiet_zone", "error_correction"} {
+
+		v, ok, err := intOption(opts, name)
+
+		if err != nil {
+			return "", err
+		}
+
+		if ok {
+			q.Set(name, strconv.Itoa(v))
+		}
+	}
+
+	return fmt.Sprintf("%s://?%s", symbology, q.Encode()), nil
+}
+
+// intOption returns the value of the integer property 'name' in the JavaScript object 'opts' and whether it is
+// defined. If 'opts' is not an object or the property is not defined false is returned.
+func intOption(opts js.Value, name string) (int, bool, error) {
+
+	if opts.Type() != js.TypeObject {
+		return 0, false, nil
+	}
+
+	v := opts.Get(name)
+
+	if v.Type() == js.TypeUndefined || v.Type() == js.TypeNull {
+		return 0, false, nil
+	}
+
+	if v.Type() != js.TypeNumber || v.Float() != float64(v.Int()) {
+		return 0, false, fmt.Errorf("Invalid %s option, expected an integer", name)
+	}
+
+	return v.Int(), true, nil
+}
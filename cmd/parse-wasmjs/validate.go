@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/sfomuseum/go-bcbp-wasm/boardingpass"
+)
+
+// ValidateFunc returns a JavaScript function which checks a BCBP string against IATA Resolution 792 and resolves
+// a Promise with a `boardingpass.Report` encoded as native JavaScript objects and arrays.
+func ValidateFunc() js.Func {
+
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		return newPromise(func(resolve js.Value, reject js.Value) {
+
+			if len(args) == 0 || args[0].Type() != js.TypeString {
+				err := fmt.Errorf("Missing or invalid BCBP string")
+				slog.Error("Failed to validate BCBP", "error", err)
+				reject.Invoke(newJSError(err, ERROR_INVALID_ARGUMENT))
+				return
+			}
+
+			bcbp_str := args[0].String()
+
+			slog.Info("Validate BCBP", "raw", bcbp_str)
+
+			report := boardingpass.Validate(bcbp_str)
+
+			obj, err := toJSValue(report)
+
+			if err != nil {
+				slog.Error("Failed to convert report", "error", err)
+				reject.Invoke(newJSError(err, boardingpass.ERROR_INTERNAL))
+				return
+			}
+
+			resolve.Invoke(obj)
+		})
+	})
+}
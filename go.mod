@@ -2,9 +2,15 @@ module github.com/sfomuseum/go-bcbp-wasm
 
 go 1.24.0
 
-require github.com/sfomuseum/go-bcbp v0.0.1
+require (
+	github.com/boombuler/barcode v1.1.0
+	github.com/makiuchi-d/gozxing v0.1.1
+	github.com/sfomuseum/go-bcbp v0.0.1
+)
 
 require (
 	github.com/aaronland/go-roster v1.0.0 // indirect
 	github.com/skrushinsky/scaliger v0.0.4 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
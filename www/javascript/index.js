@@ -30,19 +30,8 @@ async function parse() {
 
     console.log("Parse BCBP string '" + bcbp_str + "'");
     
-    parse_bcbp(bcbp_str).then(rsp => {
+    parse_bcbp_object(bcbp_str).then(bcbp_data => {
 
-	var bcbp_data;
-	
-	try {
-	    bcbp_data = JSON.parse(rsp)
-	} catch(e){
-	    result_el.innerText = "Unable to parse your BCBP string: " + e;
-	    
-	    result_el.style.display = "block";
-	    return;
-	}
-	
 	var pre = document.createElement("pre");
 	pre.innerText = JSON.stringify(bcbp_data, '', 2);
 	
@@ -52,11 +41,32 @@ async function parse() {
     }).catch(err => {
 
 	console.error("Failed to parse BCBP string ", err);
+
+	var msg = document.createElement("p");
+	msg.innerText = "There was a problem parsing your BCBP string: " + err.message + " (" + err.code + ")";
+	
+	result_el.appendChild(msg);
+
+	if (err.offset != null){
+	    result_el.appendChild(highlight(bcbp_str, err.offset, (err.raw) ? err.raw.length : 1));
+	}
 	
-	result_el.innerText = "There was a problem parsing your BCBP string:" + err;
 	result_el.style.display = "block";    	
     });
     
     return false;
 }
-    
+
+function highlight(bcbp_str, offset, length) {
+
+    var pre = document.createElement("pre");
+    pre.appendChild(document.createTextNode(bcbp_str.substring(0, offset)));
+
+    var mark = document.createElement("mark");
+    mark.innerText = bcbp_str.substring(offset, offset + Math.max(length, 1)) || " ";
+
+    pre.appendChild(mark);
+    pre.appendChild(document.createTextNode(bcbp_str.substring(offset + Math.max(length, 1))));
+
+    return pre;
+}